@@ -0,0 +1,182 @@
+package is
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+const (
+	// hexRowWidth is the number of bytes shown on each row of a hex dump.
+	hexRowWidth = 8
+
+	// hexContextRows is the number of rows shown before and after the row
+	// containing the first difference in a hex dump.
+	hexContextRows = 2
+
+	// readerChunk is the number of bytes read at a time by ReaderEqual.
+	readerChunk = 32 * 1024
+)
+
+// bytesOf returns the contents of v if it is a slice or array of bytes. Types
+// with a String method, such as net.IP, are excluded, as they are better
+// printed as text.
+func bytesOf(v reflect.Value) ([]byte, bool) {
+	if !v.IsValid() || v.Type().Implements(stringerType) {
+		return nil, false
+	}
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Type().Elem().Kind() != reflect.Uint8 {
+		return nil, false
+	}
+	b := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(b), v)
+	return b, true
+}
+
+// firstByteMismatch returns the offset of the first byte at which a and b
+// differ, or -1 if they are equal. If one is a prefix of the other, the
+// length of the shorter is returned.
+func firstByteMismatch(a []byte, b []byte) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	if len(a) != len(b) {
+		return n
+	}
+	return -1
+}
+
+// hexDiff renders side-by-side hex and ASCII dumps of actual and expected
+// around pos, the offset of their first difference. base is the offset of
+// the start of both slices in the data being compared. Rows that differ are
+// marked with an asterisk.
+func hexDiff(actual []byte, expected []byte, base int64, pos int) string {
+	row := pos / hexRowWidth
+	first := row - hexContextRows
+	if first < 0 {
+		first = 0
+	}
+	size := len(actual)
+	if len(expected) > size {
+		size = len(expected)
+	}
+	last := row + hexContextRows
+	if lastRow := (size - 1) / hexRowWidth; last > lastRow {
+		last = lastRow
+	}
+
+	var b bytes.Buffer
+	width := 4*hexRowWidth + 2
+	fmt.Fprintf(&b, "\t  %-8s  %-*s  %s\n", "offset", width, "actual", "expected")
+	for r := first; r <= last; r++ {
+		start := r * hexRowWidth
+		aRow, eRow := hexRow(actual, start), hexRow(expected, start)
+		marker := ' '
+		if !bytes.Equal(aRow, eRow) || len(aRow) != len(eRow) {
+			marker = '*'
+		}
+		fmt.Fprintf(&b, "\t%c %08x  %s  %s\n", marker, base+int64(start), hexLine(aRow), hexLine(eRow))
+	}
+	return b.String()
+}
+
+// hexRow returns the row of b beginning at start.
+func hexRow(b []byte, start int) []byte {
+	if start >= len(b) {
+		return nil
+	}
+	end := start + hexRowWidth
+	if end > len(b) {
+		end = len(b)
+	}
+	return b[start:end]
+}
+
+// hexLine formats a row of bytes as hex followed by ASCII, padded to the
+// width of a full row.
+func hexLine(row []byte) string {
+	var h, a strings.Builder
+	for i := 0; i < hexRowWidth; i++ {
+		if i > 0 {
+			h.WriteByte(' ')
+		}
+		if i >= len(row) {
+			h.WriteString("  ")
+			a.WriteByte(' ')
+			continue
+		}
+		fmt.Fprintf(&h, "%02x", row[i])
+		if row[i] >= 0x20 && row[i] < 0x7f {
+			a.WriteByte(row[i])
+		} else {
+			a.WriteByte('.')
+		}
+	}
+	return h.String() + " |" + a.String() + "|"
+}
+
+func (self *asserter) ReaderEqual(actual io.Reader, expected io.Reader) {
+	self.tb.Helper()
+	if isNil(actual) {
+		fail(self, "actual reader (%s) should not be nil", objectTypeName(actual))
+		return
+	}
+	if isNil(expected) {
+		fail(self, "expected reader (%s) should not be nil", objectTypeName(expected))
+		return
+	}
+	aBuf, eBuf := make([]byte, readerChunk), make([]byte, readerChunk)
+	var aPrev, ePrev []byte
+	var offset int64
+	for {
+		na, aErr := io.ReadFull(actual, aBuf)
+		ne, eErr := io.ReadFull(expected, eBuf)
+		if aErr != nil && aErr != io.EOF && aErr != io.ErrUnexpectedEOF {
+			fail(self, "unable to read from actual reader (%s) at byte offset %d: %v", objectTypeName(actual), offset, aErr)
+			return
+		}
+		if eErr != nil && eErr != io.EOF && eErr != io.ErrUnexpectedEOF {
+			fail(self, "unable to read from expected reader (%s) at byte offset %d: %v", objectTypeName(expected), offset, eErr)
+			return
+		}
+
+		pos := firstByteMismatch(aBuf[:na], eBuf[:ne])
+		if pos >= 0 {
+			// Include the end of the previous chunk and the start of the
+			// next, so that the dump has context on both sides.
+			context := hexContextRows * hexRowWidth
+			a := append(append([]byte(nil), aPrev...), aBuf[:na]...)
+			e := append(append([]byte(nil), ePrev...), eBuf[:ne]...)
+			a = append(a, readUpTo(actual, context)...)
+			e = append(e, readUpTo(expected, context)...)
+			fail(self, "actual reader (%s) should produce the same bytes as expected reader (%s) - First difference at byte offset %d:\n%s",
+				objectTypeName(actual), objectTypeName(expected), offset+int64(pos),
+				hexDiff(a, e, offset-int64(len(aPrev)), len(aPrev)+pos))
+			return
+		}
+		if na < readerChunk {
+			return
+		}
+
+		// Keep whole rows from the end of this chunk for context.
+		keep := hexContextRows * hexRowWidth
+		aPrev = append(aPrev[:0], aBuf[na-keep:na]...)
+		ePrev = append(ePrev[:0], eBuf[ne-keep:ne]...)
+		offset += int64(na)
+	}
+}
+
+// readUpTo reads at most n bytes from r, ignoring any error.
+func readUpTo(r io.Reader, n int) []byte {
+	b := make([]byte, n)
+	read, _ := io.ReadFull(r, b)
+	return b[:read]
+}
@@ -0,0 +1,116 @@
+package is
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestHexDiff(t *testing.T) {
+	actual := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	expected := []byte("0123456789abcdefghijKLMNopqrstuvwxyz!")
+	pos := firstByteMismatch(actual, expected)
+	if pos != 20 {
+		t.Fatalf("expected first mismatch at 20, but got %d", pos)
+	}
+	dump := "\t  offset    actual                              expected\n" +
+		"\t  00000000  30 31 32 33 34 35 36 37 |01234567|  30 31 32 33 34 35 36 37 |01234567|\n" +
+		"\t  00000008  38 39 61 62 63 64 65 66 |89abcdef|  38 39 61 62 63 64 65 66 |89abcdef|\n" +
+		"\t* 00000010  67 68 69 6a 6b 6c 6d 6e |ghijklmn|  67 68 69 6a 4b 4c 4d 4e |ghijKLMN|\n" +
+		"\t  00000018  6f 70 71 72 73 74 75 76 |opqrstuv|  6f 70 71 72 73 74 75 76 |opqrstuv|\n" +
+		"\t* 00000020  77 78 79 7a             |wxyz    |  77 78 79 7a 21          |wxyz!   |\n"
+	if d := hexDiff(actual, expected, 0, pos); d != dump {
+		t.Fatalf("expected dump:\n%s\nbut got:\n%s", dump, d)
+	}
+
+	d := hexDiff([]byte{0, 1}, []byte{0, 1, 2}, 0, 2)
+	tail := "\t* 00000000  00 01                   |..      |  00 01 02                |...     |\n"
+	if !strings.HasSuffix(d, tail) {
+		t.Fatalf("expected dump to end with:\n%s\nbut got:\n%s", tail, d)
+	}
+
+	if pos := firstByteMismatch([]byte("abc"), []byte("abc")); pos != -1 {
+		t.Fatalf("expected no mismatch, but got %d", pos)
+	}
+}
+
+func TestEqualBytes(t *testing.T) {
+	assert := New(t)
+
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	assert.Equal([]byte("hello world"), []byte("hello there"))
+	if !strings.HasPrefix(msg, "actual value ([]uint8, 11 bytes) should be equal to expected value ([]uint8, 11 bytes) - First difference at byte offset 6:\n") ||
+		!strings.Contains(msg, "|hello wo|") {
+		t.Fatalf("expected hex dump, but got:\n%s", msg)
+	}
+
+	assert.Equal([4]byte{1, 2, 3, 4}, [4]byte{1, 2, 3, 5})
+	if !strings.Contains(msg, "First difference at byte offset 3:") {
+		t.Fatalf("expected hex dump, but got:\n%s", msg)
+	}
+
+	assert.Equal(net.IPv4(127, 0, 0, 1), net.IPv4(127, 0, 0, 2))
+	if strings.Contains(msg, "First difference") {
+		t.Fatalf("expected net.IP to be printed as text, but got:\n%s", msg)
+	}
+
+	fail = failDefault
+}
+
+func TestReaderEqual(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	large := bytes.Repeat([]byte("0123456789abcdef"), readerChunk/4)
+	assert.ReaderEqual(bytes.NewReader(large), iotest.OneByteReader(bytes.NewReader(large)))
+	assert.ReaderEqual(strings.NewReader(""), strings.NewReader(""))
+	if hit != 0 {
+		t.Fatalf("expected no failures, but got: %s", msg)
+	}
+
+	changed := append([]byte(nil), large...)
+	changed[readerChunk+3] = 'X'
+	assert.ReaderEqual(bytes.NewReader(changed), bytes.NewReader(large))
+	offset := fmt.Sprintf("%08x", readerChunk)
+	if hit != 1 || !strings.Contains(msg, fmt.Sprintf("First difference at byte offset %d:\n", readerChunk+3)) ||
+		!strings.Contains(msg, "\t* "+offset+"  30 31 32 58 ") ||
+		!strings.Contains(msg, fmt.Sprintf("\t  %08x", readerChunk-16)) ||
+		!strings.Contains(msg, fmt.Sprintf("\t  %08x", readerChunk+16)) {
+		t.Fatalf("expected difference in second chunk, but got:\n%s", msg)
+	}
+
+	assert.ReaderEqual(bytes.NewReader(large), bytes.NewReader(large[:readerChunk]))
+	if hit != 2 || !strings.Contains(msg, fmt.Sprintf("First difference at byte offset %d:\n", readerChunk)) {
+		t.Fatalf("expected length difference, but got:\n%s", msg)
+	}
+
+	assert.ReaderEqual(iotest.ErrReader(errors.New("broken")), strings.NewReader("a"))
+	if hit != 3 || msg != "unable to read from actual reader (*iotest.errReader) at byte offset 0: broken" {
+		t.Fatalf("expected read failure, but got: %s", msg)
+	}
+
+	assert.ReaderEqual(nil, strings.NewReader("a"))
+	if hit != 4 || msg != "actual reader (<nil>) should not be nil" {
+		t.Fatalf("expected nil reader failure, but got: %s", msg)
+	}
+	assert.ReaderEqual(strings.NewReader("a"), (*bytes.Reader)(nil))
+	if hit != 5 || msg != "expected reader (*bytes.Reader) should not be nil" {
+		t.Fatalf("expected nil reader failure, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
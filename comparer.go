@@ -0,0 +1,322 @@
+package is
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// comparer is a function of the form func(a, b T) bool which reports whether
+// two values of type typ are equal.
+type comparer struct {
+	typ reflect.Type
+	fn  reflect.Value
+}
+
+// comparers holds comparers in the order they were added. A nil *comparers
+// holds none. It is never modified once built, so that Asserters may share
+// it.
+type comparers struct {
+	list []comparer
+
+	// cache maps a reflect.Type to the lookupResult found for it, so that
+	// the comparers are only searched once per type.
+	cache sync.Map
+}
+
+// lookupResult is the comparer found for a type, along with the comparers
+// that were consulted after the ones holding it.
+type lookupResult struct {
+	fn   reflect.Value
+	ok   bool
+	next *comparers
+}
+
+// registry holds the comparers registered with RegisterComparer. They are
+// replaced, rather than modified, when a comparer is registered, so that
+// they may be read without locking.
+var registry struct {
+	sync.Mutex
+	comparers atomic.Pointer[comparers]
+}
+
+// RegisterComparer registers fn as the equality function for values of type
+// T for every Asserter. It is consulted wherever values are compared deeply,
+// at every level of nesting, so it also applies to values of type T inside
+// slices, maps and struct fields. If T is an interface type, fn is used for
+// any pair of non-nil values whose dynamic types both implement T, even if
+// those types differ.
+//
+// Comparers added to an Asserter with WithComparer take precedence over
+// those registered with this function. Among comparers registered with this
+// function, one for the exact type is preferred over one for an interface,
+// and later registrations take precedence over earlier ones.
+// RegisterComparer is typically called from an init function or TestMain. It
+// panics if fn is nil.
+func RegisterComparer[T any](fn func(a, b T) bool) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.comparers.Store(registry.comparers.Load().with(fn))
+}
+
+// registered returns the comparers registered with RegisterComparer.
+func registered() *comparers {
+	return registry.comparers.Load()
+}
+
+// with returns a copy of c with fn added. It panics if fn is not a function
+// of the form func(a, b T) bool.
+func (c *comparers) with(fn interface{}) *comparers {
+	v := reflect.ValueOf(fn)
+	if !v.IsValid() || v.Kind() != reflect.Func || v.IsNil() {
+		panic(fmt.Sprintf("is: comparer must be a function of the form func(a, b T) bool, but got: %T", fn))
+	}
+	t := v.Type()
+	if t.NumIn() != 2 || t.In(0) != t.In(1) || t.IsVariadic() ||
+		t.NumOut() != 1 || t.Out(0).Kind() != reflect.Bool {
+		panic(fmt.Sprintf("is: comparer must be a function of the form func(a, b T) bool, but got: %T", fn))
+	}
+	out := &comparers{}
+	if c != nil {
+		out.list = make([]comparer, len(c.list), len(c.list)+1)
+		copy(out.list, c.list)
+	}
+	out.list = append(out.list, comparer{typ: t.In(0), fn: v})
+	return out
+}
+
+// find returns the comparer for values of type t, consulting c before the
+// comparers registered with RegisterComparer.
+func (c *comparers) find(t reflect.Type) (reflect.Value, bool) {
+	global := registered()
+	if c == nil {
+		return global.lookup(t, nil)
+	}
+	return c.lookup(t, global)
+}
+
+// lookup returns the comparer for values of type t from c, falling back to
+// next. The result is cached for as long as next is unchanged.
+func (c *comparers) lookup(t reflect.Type, next *comparers) (reflect.Value, bool) {
+	if c == nil {
+		return reflect.Value{}, false
+	}
+	if v, ok := c.cache.Load(t); ok {
+		if r := v.(lookupResult); r.next == next {
+			return r.fn, r.ok
+		}
+	}
+	fn, ok := c.search(t)
+	if !ok {
+		fn, ok = next.lookup(t, nil)
+	}
+	c.cache.Store(t, lookupResult{fn: fn, ok: ok, next: next})
+	return fn, ok
+}
+
+// search returns the comparer in c for values of type t. A comparer for t
+// itself is preferred over one for an interface t implements, and later
+// comparers are preferred over earlier ones.
+func (c *comparers) search(t reflect.Type) (reflect.Value, bool) {
+	for i := len(c.list) - 1; i >= 0; i-- {
+		if c.list[i].typ == t {
+			return c.list[i].fn, true
+		}
+	}
+	for i := len(c.list) - 1; i >= 0; i-- {
+		it := c.list[i].typ
+		if it.Kind() == reflect.Interface && t.Implements(it) {
+			return c.list[i].fn, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// compare calls the comparer for a and e. Values of the same type use the
+// comparer for that type, while values of different types use a comparer for
+// an interface both implement. Values held in interfaces are not compared
+// here, but once unwrapped, so that the comparer is chosen by their dynamic
+// types. It returns false for handled if there is no comparer for the types,
+// if the comparer is for an interface and either value is nil, or if the
+// values cannot be accessed.
+func (c *comparers) compare(a, e reflect.Value) (equal, handled bool) {
+	if a.Kind() == reflect.Interface || e.Kind() == reflect.Interface {
+		return false, false
+	}
+	var fn reflect.Value
+	var ok bool
+	if a.Type() == e.Type() {
+		fn, ok = c.find(a.Type())
+	} else {
+		fn, ok = c.findCommon(a.Type(), e.Type())
+	}
+	if !ok {
+		return false, false
+	}
+	in := fn.Type().In(0)
+	if in.Kind() == reflect.Interface && (isNilValue(a) || isNilValue(e)) {
+		return false, false
+	}
+	av, ok := accessible(a)
+	if !ok {
+		return false, false
+	}
+	ev, ok := accessible(e)
+	if !ok {
+		return false, false
+	}
+	return fn.Call([]reflect.Value{av.Convert(in), ev.Convert(in)})[0].Bool(), true
+}
+
+// findCommon returns the comparer for an interface implemented by both x and
+// y, consulting c before the comparers registered with RegisterComparer.
+func (c *comparers) findCommon(x, y reflect.Type) (reflect.Value, bool) {
+	for _, cs := range []*comparers{c, registered()} {
+		if cs == nil {
+			continue
+		}
+		for i := len(cs.list) - 1; i >= 0; i-- {
+			it := cs.list[i].typ
+			if it.Kind() == reflect.Interface && x.Implements(it) && y.Implements(it) {
+				return cs.list[i].fn, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// isNilValue reports whether v is a nil pointer, map, slice, chan, func or
+// interface.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.Interface, reflect.UnsafePointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// accessible returns a copy of v that may be passed to functions, even if v
+// was obtained through unexported struct fields. This is only possible if v
+// is addressable.
+func accessible(v reflect.Value) (reflect.Value, bool) {
+	if v.CanInterface() {
+		return v, true
+	}
+	if v.CanAddr() {
+		return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem(), true
+	}
+	return reflect.Value{}, false
+}
+
+// addressable returns an addressable copy of v, so that the values of
+// unexported fields found beneath it remain accessible. Values that were
+// themselves obtained through unexported fields cannot be copied, and are
+// returned as is.
+func addressable(v reflect.Value) reflect.Value {
+	if !v.IsValid() || v.CanAddr() || !v.CanInterface() {
+		return v
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+var (
+	equalerType         = reflect.TypeOf((*Equaler)(nil)).Elem()
+	equalityCheckerType = reflect.TypeOf((*EqualityChecker)(nil)).Elem()
+)
+
+// checkerEqual compares a and b using Equaler or EqualityChecker, if either
+// implements them. Both values are consulted when they implement one of the
+// interfaces, so that the order of the arguments does not change the result.
+func checkerEqual(a interface{}, b interface{}) (equal, handled bool) {
+	equal = true
+	for _, pair := range [][2]interface{}{{a, b}, {b, a}} {
+		switch x := pair[0].(type) {
+		case Equaler:
+			handled = true
+			equal = x.Equal(pair[1]) && equal
+		case EqualityChecker:
+			handled = true
+			equal = x.IsEqual(pair[1]) && equal
+		}
+	}
+	return equal, handled
+}
+
+// methodEqual compares a and e, which must be of the same type, using an
+// equality method of that type. Equaler and EqualityChecker are used if
+// implemented, followed by a method of the form Equal(T) bool, such as
+// time.Time.Equal or net.IP.Equal. If only the pointer type has such a
+// method, it is used for addressable values. The method of both values is
+// called, so that the order of the arguments does not change the result. It
+// returns false for handled if the type has no equality method.
+func methodEqual(a, e reflect.Value) (equal, handled bool) {
+	switch a.Kind() {
+	case reflect.Interface:
+		return false, false
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		// Equality methods are not expected to handle nil receivers.
+		if a.IsNil() || e.IsNil() {
+			return false, false
+		}
+	}
+	av, ok := accessible(a)
+	if !ok {
+		return false, false
+	}
+	ev, ok := accessible(e)
+	if !ok {
+		return false, false
+	}
+	if f := equalityFunc(av.Type()); f != nil {
+		return f(av, ev) && f(ev, av), true
+	}
+	if av.CanAddr() && ev.CanAddr() {
+		if f := equalityFunc(reflect.PtrTo(av.Type())); f != nil {
+			return f(av.Addr(), ev.Addr()) && f(ev.Addr(), av.Addr()), true
+		}
+	}
+	return false, false
+}
+
+// equalityFuncs caches the result of equalityFunc for each type.
+var equalityFuncs sync.Map
+
+// equalityFunc returns a function that compares two values of type t using
+// an equality method of t, or nil if t has none.
+func equalityFunc(t reflect.Type) func(x, y reflect.Value) bool {
+	if f, ok := equalityFuncs.Load(t); ok {
+		return f.(func(x, y reflect.Value) bool)
+	}
+	f := findEqualityFunc(t)
+	equalityFuncs.Store(t, f)
+	return f
+}
+
+// findEqualityFunc looks up the equality method of t for equalityFunc.
+func findEqualityFunc(t reflect.Type) func(x, y reflect.Value) bool {
+	switch {
+	case t.Implements(equalerType):
+		return func(x, y reflect.Value) bool {
+			return x.Interface().(Equaler).Equal(y.Interface())
+		}
+	case t.Implements(equalityCheckerType):
+		return func(x, y reflect.Value) bool {
+			return x.Interface().(EqualityChecker).IsEqual(y.Interface())
+		}
+	}
+	m, ok := t.MethodByName("Equal")
+	if !ok || t.Kind() == reflect.Interface {
+		return nil
+	}
+	mt := m.Type
+	if mt.NumIn() != 2 || mt.In(1) != t || mt.NumOut() != 1 || mt.Out(0).Kind() != reflect.Bool {
+		return nil
+	}
+	return func(x, y reflect.Value) bool {
+		return m.Func.Call([]reflect.Value{x, y})[0].Bool()
+	}
+}
@@ -0,0 +1,274 @@
+package is
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type comparerEvent struct {
+	Name string
+	At   time.Time
+	at   time.Time
+}
+
+type comparerID struct {
+	value string
+}
+
+type comparerNamer interface {
+	CompareName() string
+}
+
+type comparerNamed struct {
+	name  string
+	extra int
+}
+
+func (c comparerNamed) CompareName() string {
+	return c.name
+}
+
+func (c comparerNamed) String() string {
+	return fmt.Sprintf("%s/%d", c.name, c.extra)
+}
+
+func TestWithComparer(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := now.Add(time.Millisecond)
+	a := []comparerEvent{{Name: "a", At: now, at: now}}
+	b := []comparerEvent{{Name: "a", At: later, at: later}}
+
+	assert.Equal(a, b)
+	if hit != 1 {
+		t.Fatalf("expected times to differ without a comparer")
+	}
+
+	seconds := assert.WithComparer(func(a, b time.Time) bool {
+		return a.Truncate(time.Second).Equal(b.Truncate(time.Second))
+	})
+	seconds.Equal(a, b)
+	seconds.Equal(map[string]comparerEvent{"x": a[0]}, map[string]comparerEvent{"x": b[0]})
+	seconds.Msg("message").Equal(a, b)
+	seconds.ElementsMatch(a, b)
+	seconds.Subset(a[0], comparerEvent{At: later})
+	if hit != 1 {
+		t.Fatalf("expected comparer to be used, but got: %s", msg)
+	}
+
+	b[0].At = now.Add(time.Second)
+	seconds.Equal(a, b)
+	if hit != 2 || !strings.Contains(msg, "[0].At: ") {
+		t.Fatalf("expected failure at [0].At, but got: %s", msg)
+	}
+
+	named := assert.WithComparer(func(a, b comparerNamer) bool { return a.CompareName() == b.CompareName() })
+	named.Equal([]comparerNamed{{"x", 1}}, []comparerNamed{{"x", 2}})
+	if hit != 2 {
+		t.Fatalf("expected interface comparer to be used, but got: %s", msg)
+	}
+
+	assert.ShouldPanic(func() { assert.WithComparer(func(a int, b string) bool { return false }) })
+	assert.ShouldPanic(func() { assert.WithComparer(42) })
+	assert.ShouldPanic(func() { assert.WithComparer(nil) })
+	assert.ShouldPanic(func() { assert.WithComparer((func(a, b int) bool)(nil)) })
+
+	// When several interface comparers apply, the last one added is used.
+	either := assert.WithComparer(func(a, b comparerNamer) bool { return false }).
+		WithComparer(func(a, b fmt.Stringer) bool { return true })
+	for i := 0; i < 10; i++ {
+		either.Equal(comparerNamed{"x", 1}, comparerNamed{"x", 2})
+	}
+	if hit != 2 {
+		t.Fatalf("expected the last matching comparer to be used, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
+
+// comparerErr is an error whose message is the same as that of an
+// errors.New error, but with a different type.
+type comparerErr string
+
+func (e comparerErr) Error() string { return string(e) }
+
+func TestInterfaceComparer(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	messages := assert.WithComparer(func(x, y error) bool { return x.Error() == y.Error() })
+	type result struct{ Err error }
+	messages.Equal(result{}, result{})
+	messages.Equal(result{errors.New("x")}, result{comparerErr("x")})
+	messages.Equal([]interface{}{errors.New("x")}, []interface{}{comparerErr("x")})
+	messages.Equal(errors.New("x"), comparerErr("x"))
+	if hit != 0 {
+		t.Fatalf("expected the comparer to be chosen by dynamic types, but got: %s", msg)
+	}
+
+	messages.Equal(result{}, result{errors.New("x")})
+	if hit != 1 || !strings.Contains(msg, ".Err: ") {
+		t.Fatalf("expected a nil error not to reach the comparer, but got: %s", msg)
+	}
+	messages.Equal(result{(*errCode)(nil)}, result{errors.New("x")})
+	if hit != 2 {
+		t.Fatalf("expected a nil error not to reach the comparer, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
+
+func TestRegisterComparer(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+	}
+
+	saved := registered()
+	defer registry.comparers.Store(saved)
+	RegisterComparer(func(a, b comparerID) bool { return strings.EqualFold(a.value, b.value) })
+
+	assert.Equal(comparerID{"abc"}, comparerID{"ABC"})
+	assert.Equal([]comparerID{{"abc"}}, []comparerID{{"ABC"}})
+	if hit != 0 {
+		t.Fatalf("expected registered comparer to be used")
+	}
+
+	local := assert.WithComparer(func(a, b comparerID) bool { return a.value == b.value })
+	local.Equal(comparerID{"abc"}, comparerID{"ABC"})
+	if hit != 1 {
+		t.Fatalf("expected local comparer to take precedence")
+	}
+
+	RegisterComparer(func(a, b comparerID) bool { return true })
+	assert.Equal(comparerID{"abc"}, comparerID{"xyz"})
+	if hit != 1 {
+		t.Fatalf("expected later registration to take precedence")
+	}
+
+	RegisterComparer(func(a, b comparerNamer) bool { return a.CompareName() == b.CompareName() })
+	assert.Equal(comparerNamed{"x", 1}, comparerNamed{"x", 2})
+	if hit != 1 {
+		t.Fatalf("expected comparer registered after the first lookup to be used")
+	}
+
+	strictNames := assert.WithComparer(func(a, b comparerNamer) bool { return a == b })
+	strictNames.Equal(comparerNamed{"x", 1}, comparerNamed{"x", 2})
+	if hit != 2 {
+		t.Fatalf("expected local interface comparer to take precedence")
+	}
+
+	assert.ShouldPanic(func() { RegisterComparer[int](nil) })
+
+	fail = failDefault
+}
+
+type nestedEvent struct {
+	ID    int
+	Nonce int
+}
+
+func (e nestedEvent) IsEqual(in interface{}) bool {
+	o, ok := in.(nestedEvent)
+	return ok && e.ID == o.ID
+}
+
+type nestedPtrChecker struct {
+	ID    int
+	Nonce int
+}
+
+func (e *nestedPtrChecker) IsEqual(in interface{}) bool {
+	o, ok := in.(*nestedPtrChecker)
+	return ok && e.ID == o.ID
+}
+
+type nestedRecord struct {
+	Events []nestedEvent
+	Ptrs   []nestedPtrChecker
+	At     time.Time
+	IP     net.IP
+	at     time.Time
+}
+
+// lenient considers itself equal to anything with a non-negative value, which
+// makes it asymmetric.
+type lenient int
+
+func (l lenient) IsEqual(in interface{}) bool {
+	o, ok := in.(lenient)
+	return ok && o >= 0
+}
+
+func TestNestedEquality(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	now := time.Now()
+	a := nestedRecord{
+		Events: []nestedEvent{{1, 10}, {2, 20}},
+		Ptrs:   []nestedPtrChecker{{1, 10}},
+		At:     now,
+		IP:     net.ParseIP("10.0.0.1"),
+		at:     now,
+	}
+	b := nestedRecord{
+		Events: []nestedEvent{{1, 11}, {2, 21}},
+		Ptrs:   []nestedPtrChecker{{1, 11}},
+		At:     now.UTC(),
+		IP:     net.IPv4(10, 0, 0, 1).To4(),
+		at:     now.In(time.FixedZone("test", 3600)),
+	}
+
+	assert.Equal(a, b)
+	assert.Equal(&a, &b)
+	assert.Equal(map[string]nestedRecord{"a": a}, map[string]nestedRecord{"a": b})
+	assert.Equal([]time.Time{now}, []time.Time{now.UTC()})
+	assert.NotEqual(a.Events, []nestedEvent{{1, 10}, {3, 20}})
+	if hit != 0 {
+		t.Fatalf("expected nested equality methods to be used, but got: %s", msg)
+	}
+
+	b.Events[1].ID = 3
+	assert.Equal(a, b)
+	if hit != 1 || !strings.Contains(msg, ".Events[1]: ") {
+		t.Fatalf("expected failure at .Events[1], but got: %s", msg)
+	}
+
+	hit = 0
+	assert.Equal(lenient(1), lenient(-1))
+	assert.Equal(lenient(-1), lenient(1))
+	assert.Equal([]lenient{1}, []lenient{-1})
+	assert.Equal([]lenient{-1}, []lenient{1})
+	if hit != 4 {
+		t.Fatalf("expected argument order not to change the result, but got %d failures", hit)
+	}
+
+	fail = failDefault
+}
@@ -0,0 +1,465 @@
+package is
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// maxDiffs is the maximum number of differences printed in a failure
+// message. Any further differences are summarized in a single line.
+const maxDiffs = 20
+
+// difference describes a single mismatch found while walking two values.
+type difference struct {
+	path     string
+	actual   string
+	expected string
+	note     string
+}
+
+// visit records a pair of pointers that have already been compared, which
+// allows cyclic structures to be walked without looping forever.
+type visit struct {
+	a, e uintptr
+	typ  reflect.Type
+}
+
+// differ walks two values in parallel using reflection and records every
+// location at which they differ.
+type differ struct {
+	diffs   []difference
+	visited map[visit]bool
+
+	// numeric, if set, is used to compare pairs of numeric values, even if
+	// their types differ. It returns whether the values should be considered
+	// equal, along with a note to print if they are not.
+	numeric func(a, e reflect.Value) (note string, equal bool)
+
+	// subset, if set, ignores zero-valued struct fields in the expected value
+	// and map keys that are present only in the actual value.
+	subset bool
+
+	// quick, if set, stops the walk at the first difference found.
+	quick bool
+
+	// comparers are consulted for every pair of values, by their dynamic
+	// types.
+	comparers *comparers
+}
+
+func newDiffer(c *comparers) *differ {
+	return &differ{visited: make(map[visit]bool), comparers: c}
+}
+
+// run compares actual and expected from the top level and returns every
+// difference found.
+func (d *differ) run(actual, expected reflect.Value) []difference {
+	d.compare("", addressable(actual), addressable(expected))
+	return d.diffs
+}
+
+// report records a difference at path between the values a and e. In quick
+// mode, only the fact that a difference was found is recorded.
+func (d *differ) report(path string, a, e reflect.Value) {
+	if d.quick {
+		d.diffs = append(d.diffs, difference{})
+		return
+	}
+	as, es := formatValue(a), formatValue(e)
+	if a.IsValid() && e.IsValid() && a.Type() != e.Type() {
+		as = formatTyped(a)
+		es = formatTyped(e)
+	}
+	d.diffs = append(d.diffs, difference{path: path, actual: as, expected: es})
+}
+
+// missing records a difference at path where only one side has a value.
+func (d *differ) missing(path string, a, e reflect.Value) {
+	if d.quick {
+		d.diffs = append(d.diffs, difference{})
+		return
+	}
+	as, es := "<missing>", "<missing>"
+	if a.IsValid() {
+		as = formatValue(a)
+	}
+	if e.IsValid() {
+		es = formatValue(e)
+	}
+	d.diffs = append(d.diffs, difference{path: path, actual: as, expected: es})
+}
+
+// compare walks a and e, recording every difference found beneath path.
+func (d *differ) compare(path string, a, e reflect.Value) {
+	if d.quick && len(d.diffs) > 0 {
+		return
+	}
+	if !a.IsValid() || !e.IsValid() {
+		if a.IsValid() != e.IsValid() {
+			d.report(path, a, e)
+		}
+		return
+	}
+	if d.numeric != nil && isNumber(a) && isNumber(e) {
+		if note, equal := d.numeric(a, e); !equal {
+			d.report(path, a, e)
+			d.diffs[len(d.diffs)-1].note = note
+		}
+		return
+	}
+	if equal, ok := d.comparers.compare(a, e); ok {
+		if !equal {
+			d.report(path, a, e)
+		}
+		return
+	}
+	if a.Type() == e.Type() {
+		if equal, ok := methodEqual(a, e); ok {
+			if !equal {
+				d.report(path, a, e)
+			}
+			return
+		}
+	}
+	if a.Type() != e.Type() {
+		if d.numeric != nil && isList(a) && isList(e) {
+			// Lists of different numeric types are compared element by
+			// element, as the elements themselves may be compared.
+			d.compareElems(path, a, e)
+			return
+		}
+		d.report(path, a, e)
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || e.IsNil() {
+			if a.IsNil() != e.IsNil() {
+				d.report(path, a, e)
+			}
+			return
+		}
+		if d.seen(a, e) {
+			return
+		}
+		d.compare(path, a.Elem(), e.Elem())
+	case reflect.Interface:
+		if a.IsNil() || e.IsNil() {
+			if a.IsNil() != e.IsNil() {
+				d.report(path, a, e)
+			}
+			return
+		}
+		d.compare(path, a.Elem(), e.Elem())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if d.subset && e.Field(i).IsZero() {
+				continue
+			}
+			d.compare(d.fieldPath(path, a.Type().Field(i).Name), a.Field(i), e.Field(i))
+		}
+	case reflect.Slice:
+		if a.IsNil() != e.IsNil() {
+			d.report(path, a, e)
+			return
+		}
+		if a.Pointer() == e.Pointer() && a.Len() == e.Len() {
+			return
+		}
+		if d.seen(a, e) {
+			return
+		}
+		d.compareElems(path, a, e)
+	case reflect.Array:
+		d.compareElems(path, a, e)
+	case reflect.Map:
+		if a.IsNil() != e.IsNil() {
+			d.report(path, a, e)
+			return
+		}
+		if a.Pointer() == e.Pointer() {
+			return
+		}
+		if d.seen(a, e) {
+			return
+		}
+		d.compareMaps(path, a, e)
+	default:
+		if !equalLeaf(a, e) {
+			d.report(path, a, e)
+		}
+	}
+}
+
+// plainLeaf reports whether values of type t are compared by equalLeaf
+// alone, as t is a basic type without a comparer or an equality method.
+func (d *differ) plainLeaf(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.String:
+	default:
+		return false
+	}
+	if _, ok := d.comparers.find(t); ok {
+		return false
+	}
+	return equalityFunc(t) == nil && equalityFunc(reflect.PtrTo(t)) == nil
+}
+
+// isList reports whether v is a slice or an array.
+func isList(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+}
+
+// compareElems compares the elements of two slices or arrays index by index.
+func (d *differ) compareElems(path string, a, e reflect.Value) {
+	n := a.Len()
+	if e.Len() > n {
+		n = e.Len()
+	}
+	if d.numeric == nil && a.Type().Elem() == e.Type().Elem() && d.plainLeaf(a.Type().Elem()) {
+		// Skip the checks made by compare for every element, which cannot
+		// apply to them.
+		common := a.Len()
+		if e.Len() < common {
+			common = e.Len()
+		}
+		for i := 0; i < common; i++ {
+			if !equalLeaf(a.Index(i), e.Index(i)) {
+				d.report(d.indexPath(path, i), a.Index(i), e.Index(i))
+				if d.quick {
+					return
+				}
+			}
+		}
+		for i := e.Len(); i < a.Len(); i++ {
+			d.missing(d.indexPath(path, i), a.Index(i), reflect.Value{})
+		}
+		for i := a.Len(); i < e.Len(); i++ {
+			d.missing(d.indexPath(path, i), reflect.Value{}, e.Index(i))
+		}
+		return
+	}
+	for i := 0; i < n; i++ {
+		p := d.indexPath(path, i)
+		switch {
+		case i >= a.Len():
+			d.missing(p, reflect.Value{}, e.Index(i))
+		case i >= e.Len():
+			d.missing(p, a.Index(i), reflect.Value{})
+		default:
+			d.compare(p, a.Index(i), e.Index(i))
+		}
+	}
+}
+
+// compareMaps compares two maps key by key, in a stable order.
+func (d *differ) compareMaps(path string, a, e reflect.Value) {
+	var keys []reflect.Value
+	if !d.subset {
+		keys = a.MapKeys()
+	}
+	for _, k := range e.MapKeys() {
+		if d.subset || !a.MapIndex(k).IsValid() {
+			keys = append(keys, k)
+		}
+	}
+	if !d.quick {
+		sortValues(keys)
+	}
+	for _, k := range keys {
+		p := d.keyPath(path, k)
+		av, ev := a.MapIndex(k), e.MapIndex(k)
+		if !av.IsValid() || !ev.IsValid() {
+			d.missing(p, av, ev)
+			continue
+		}
+		// Map values are not addressable, so they are copied to keep their
+		// unexported fields accessible to comparers.
+		d.compare(p, addressable(av), addressable(ev))
+	}
+}
+
+// Paths are only built outside of quick mode, where differences are
+// reported, as building them for every element is costly.
+
+// fieldPath returns the path of the struct field name beneath path.
+func (d *differ) fieldPath(path string, name string) string {
+	if d.quick {
+		return ""
+	}
+	return path + "." + name
+}
+
+// indexPath returns the path of the element at index i beneath path.
+func (d *differ) indexPath(path string, i int) string {
+	if d.quick {
+		return ""
+	}
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+// keyPath returns the path of the map value for key k beneath path.
+func (d *differ) keyPath(path string, k reflect.Value) string {
+	if d.quick {
+		return ""
+	}
+	return fmt.Sprintf("%s[%s]", path, formatValue(k))
+}
+
+// seen reports whether the pair of references a and e has already been
+// visited, marking it as visited if not.
+func (d *differ) seen(a, e reflect.Value) bool {
+	v := visit{a: a.Pointer(), e: e.Pointer(), typ: a.Type()}
+	if d.visited[v] {
+		return true
+	}
+	d.visited[v] = true
+	return false
+}
+
+// equalLeaf compares two values of the same, non-composite type using the
+// same rules as reflect.DeepEqual. It does not require the values to be
+// exported.
+func equalLeaf(a, e reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Bool:
+		return a.Bool() == e.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == e.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == e.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == e.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == e.Complex()
+	case reflect.String:
+		return a.String() == e.String()
+	case reflect.Chan, reflect.UnsafePointer:
+		return a.Pointer() == e.Pointer()
+	case reflect.Func:
+		// Functions are only equal if both are nil, as in reflect.DeepEqual.
+		return a.IsNil() && e.IsNil()
+	}
+	return false
+}
+
+// formatValue returns a Go-syntax representation of v.
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<nil>"
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Sprint(f)
+		}
+	}
+	return fmt.Sprintf("%#v", v)
+}
+
+// formatTyped returns a representation of v that always includes its type.
+func formatTyped(v reflect.Value) string {
+	s := formatValue(v)
+	switch v.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.String:
+		return fmt.Sprintf("%s(%s)", v.Type(), s)
+	}
+	return s
+}
+
+// sortValues sorts values by their formatted representation so that map
+// keys of any type are printed in a stable order.
+func sortValues(vs []reflect.Value) {
+	sort.SliceStable(vs, func(i, j int) bool {
+		a, b := vs[i], vs[j]
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		case reflect.String:
+			return a.String() < b.String()
+		}
+		return formatValue(a) < formatValue(b)
+	})
+}
+
+// formatDiffs renders a list of differences, one per line.
+func formatDiffs(diffs []difference) string {
+	var b bytes.Buffer
+	for i, d := range diffs {
+		if i == maxDiffs {
+			fmt.Fprintf(&b, "\t... and %d more differences\n", len(diffs)-maxDiffs)
+			break
+		}
+		path := d.path
+		if path == "" {
+			path = "(root)"
+		}
+		if d.note != "" {
+			fmt.Fprintf(&b, "\t%s: %s != %s (%s)\n", path, d.actual, d.expected, d.note)
+			continue
+		}
+		fmt.Fprintf(&b, "\t%s: %s != %s\n", path, d.actual, d.expected)
+	}
+	return b.String()
+}
+
+// diff returns a description of every difference between actual and
+// expected, each prefixed with the path at which it was found. It returns
+// an empty string if no differences are found, or if the only difference is
+// between the top-level values themselves, as those are already printed in
+// the failure message.
+func diff(actual interface{}, expected interface{}, c *comparers) string {
+	a := reflect.ValueOf(actual)
+	e := reflect.ValueOf(expected)
+	if !a.IsValid() || !e.IsValid() {
+		return ""
+	}
+	// Mirror isEqual, which compares values of convertible types as though
+	// they were the same type.
+	if a.Type() != e.Type() && e.Type().ConvertibleTo(a.Type()) {
+		e = e.Convert(a.Type())
+	}
+
+	diffs := newDiffer(c).run(a, e)
+	if len(diffs) == 0 {
+		return ""
+	}
+	if len(diffs) == 1 && diffs[0].path == "" && a.Kind() != reflect.Ptr {
+		return ""
+	}
+	return " - Diff (actual != expected):\n" + formatDiffs(diffs)
+}
+
+// subsetDiff compares actual and expected, recursing into composite values,
+// and returns every difference found. Struct fields that are zero in expected
+// and map keys that are not present in expected are ignored.
+func subsetDiff(actual interface{}, expected interface{}, c *comparers) []difference {
+	d := newDiffer(c)
+	d.subset = true
+	return d.run(reflect.ValueOf(actual), reflect.ValueOf(expected))
+}
+
+// deepEqual reports whether a and e are deeply equal. It follows the same
+// rules as reflect.DeepEqual, but consults comparers at every level.
+func deepEqual(a, e reflect.Value, c *comparers) bool {
+	d := newDiffer(c)
+	d.quick = true
+	return len(d.run(a, e)) == 0
+}
@@ -0,0 +1,148 @@
+package is
+
+import (
+	"strings"
+	"testing"
+)
+
+type diffInner struct {
+	Name string
+	tags []string
+}
+
+type diffKey struct {
+	A int
+}
+
+type diffOuter struct {
+	ID    int
+	Inner *diffInner
+	Map   map[diffKey]float64
+	Any   interface{}
+}
+
+type diffCycle struct {
+	N    int
+	Next *diffCycle
+}
+
+func TestDiff(t *testing.T) {
+	a := &diffCycle{N: 1}
+	a.Next = a
+	b := &diffCycle{N: 2}
+	b.Next = b
+
+	tests := []struct {
+		actual   interface{}
+		expected interface{}
+		lines    []string
+	}{
+		{
+			actual:   []int{1, 2, 3},
+			expected: []int{1, 3},
+			lines:    []string{"[1]: 2 != 3", "[2]: 3 != <missing>"},
+		},
+		{
+			actual:   [2]float64{1, 2},
+			expected: [2]float64{1, 3},
+			lines:    []string{"[1]: 2 != 3"},
+		},
+		{
+			actual:   map[string]interface{}{"a": 1},
+			expected: map[string]interface{}{"a": 1.0},
+			lines:    []string{`["a"]: int(1) != float64(1)`},
+		},
+		{
+			actual: diffOuter{
+				ID:    1,
+				Inner: &diffInner{Name: "a", tags: []string{"x"}},
+				Map:   map[diffKey]float64{{A: 1}: 1},
+				Any:   1,
+			},
+			expected: diffOuter{
+				ID:    2,
+				Inner: &diffInner{Name: "b"},
+				Map:   map[diffKey]float64{{A: 2}: 1},
+				Any:   1.0,
+			},
+			lines: []string{
+				".ID: 1 != 2",
+				`.Inner.Name: "a" != "b"`,
+				`.Inner.tags: []string{"x"} != []string(nil)`,
+				".Map[is.diffKey{A:1}]: 1 != <missing>",
+				".Map[is.diffKey{A:2}]: <missing> != 1",
+				".Any: int(1) != float64(1)",
+			},
+		},
+		{
+			actual:   &diffInner{Name: "a"},
+			expected: &diffInner{Name: "b"},
+			lines:    []string{`.Name: "a" != "b"`},
+		},
+		{
+			actual:   a,
+			expected: b,
+			lines:    []string{".N: 1 != 2"},
+		},
+		{
+			actual:   1,
+			expected: 2,
+		},
+		{
+			actual:   []int{1},
+			expected: []int{1},
+		},
+	}
+
+	for i, test := range tests {
+		d := diff(test.actual, test.expected, nil)
+		if len(test.lines) == 0 {
+			if d != "" {
+				t.Fatalf("(test #%d) expected no diff, but got: %s", i, d)
+			}
+			continue
+		}
+		for _, l := range test.lines {
+			if !strings.Contains(d, "\t"+l+"\n") {
+				t.Fatalf("(test #%d) expected diff to contain %q, but got: %s", i, l, d)
+			}
+		}
+		if n := strings.Count(d, "\n") - 1; n != len(test.lines) {
+			t.Fatalf("(test #%d) expected %d differences, but got %d: %s", i, len(test.lines), n, d)
+		}
+	}
+}
+
+func TestDiffLimit(t *testing.T) {
+	a := make([]int, maxDiffs+5)
+	e := make([]int, maxDiffs+5)
+	for i := range e {
+		e[i] = i + 1
+	}
+	d := diff(a, e, nil)
+	if !strings.Contains(d, "... and 5 more differences") {
+		t.Fatalf("expected diff to be truncated, but got: %s", d)
+	}
+}
+
+func TestDeepEqualAllocs(t *testing.T) {
+	a := make([]int, 1<<16)
+	b := make([]int, 1<<16)
+	allocs := testing.AllocsPerRun(10, func() {
+		if !isEqual(a, b, nil) {
+			t.Fatal("expected values to be equal")
+		}
+	})
+	// Paths to elements are only built when a difference is reported, so
+	// the allocations do not grow with the number of elements.
+	if allocs > 20 {
+		t.Fatalf("expected equal values to be compared without allocating per element, but got %v allocations", allocs)
+	}
+	b[1<<15] = 1
+	if isEqual(a, b, nil) {
+		t.Fatal("expected values to differ")
+	}
+	if d := diff(a, b, nil); !strings.Contains(d, "[32768]: 0 != 1") {
+		t.Fatalf("expected the difference to be reported at its index, but got: %s", d)
+	}
+}
@@ -0,0 +1,210 @@
+package is
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// asserterOf returns the asserter underlying a, so that the generic
+// assertions share the failure handling of the Asserter methods. Asserters
+// implemented outside of this package are wrapped in a new asserter.
+func asserterOf(a Asserter) *asserter {
+	if is, ok := a.(*asserter); ok {
+		return is
+	}
+	return &asserter{tb: a.TB(), strict: true, context: diffContext}
+}
+
+// Equal compares the provided values and fails if they are not equal. Unlike
+// Asserter.Equal, both values must be of the same type, so mistakes such as
+// comparing a *User to a User are caught at compile time. Equality methods
+// and comparers are consulted in the same way as Asserter.Equal, and values
+// without them are compared using ==.
+func Equal[T comparable](a Asserter, actual T, expected T) {
+	is := asserterOf(a)
+	is.tb.Helper()
+	equal, err := comparableEqual(actual, expected, is.comparers)
+	if err != nil {
+		is.failUncomparable(actual, expected, err)
+		return
+	}
+	if !equal {
+		is.failNotEqual(actual, expected)
+	}
+}
+
+// NotEqual compares the provided values in the same way as Equal and fails
+// if they are equal.
+func NotEqual[T comparable](a Asserter, actual T, expected T) {
+	is := asserterOf(a)
+	is.tb.Helper()
+	equal, err := comparableEqual(actual, expected, is.comparers)
+	if err != nil {
+		is.failUncomparable(actual, expected, err)
+		return
+	}
+	if equal {
+		fail(is, "actual value '%v' (%s) should not be equal to expected value '%v' (%s)",
+			actual, objectTypeName(actual),
+			expected, objectTypeName(expected))
+	}
+}
+
+// failUncomparable reports that actual and expected could not be compared
+// by comparableEqual.
+func (self *asserter) failUncomparable(actual interface{}, expected interface{}, err error) {
+	self.tb.Helper()
+	fail(self, "unable to compare actual value '%v' (%s) to expected value '%v' (%s): %v",
+		actual, objectTypeName(actual),
+		expected, objectTypeName(expected), err)
+}
+
+// comparableEqual compares two values of a comparable type. Equaler,
+// EqualityChecker, comparers and equality methods such as time.Time.Equal
+// are consulted first, followed by ==. When T is an interface type, its
+// dynamic values may not be comparable, in which case an error is returned
+// rather than a panic.
+func comparableEqual[T comparable](actual T, expected T, c *comparers) (equal bool, err error) {
+	a, e := interface{}(actual), interface{}(expected)
+	if equal, ok := checkerEqual(a, e); ok {
+		return equal, nil
+	}
+	av, ev := reflect.ValueOf(a), reflect.ValueOf(e)
+	if av.IsValid() && ev.IsValid() {
+		av, ev = addressable(av), addressable(ev)
+		if equal, ok := c.compare(av, ev); ok {
+			return equal, nil
+		}
+		if av.Type() == ev.Type() {
+			if equal, ok := methodEqual(av, ev); ok {
+				return equal, nil
+			}
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return actual == expected, nil
+}
+
+// DeepEqual performs a deep compare of the provided values and fails if they
+// are not equal. It uses the same rules as Asserter.Equal, but requires both
+// values to be of the same type, which allows slices, maps and other
+// non-comparable types to be checked at compile time.
+func DeepEqual[T any](a Asserter, actual T, expected T) {
+	is := asserterOf(a)
+	is.tb.Helper()
+	if !isEqual(actual, expected, is.comparers) {
+		is.failNotEqual(actual, expected)
+	}
+}
+
+// Contains fails if the provided slice does not contain the provided element.
+// Elements are compared in the same way as Equal.
+func Contains[T comparable](a Asserter, s []T, elem T) {
+	is := asserterOf(a)
+	is.tb.Helper()
+	for _, v := range s {
+		equal, err := comparableEqual(v, elem, is.comparers)
+		if err != nil {
+			is.failUncomparable(v, elem, err)
+			return
+		}
+		if equal {
+			return
+		}
+	}
+	fail(is, "expected '%v' (%s) to contain '%v' (%s)",
+		s, objectTypeName(s),
+		elem, objectTypeName(elem))
+}
+
+// NotContains fails if the provided slice contains the provided element.
+// Elements are compared in the same way as Equal.
+func NotContains[T comparable](a Asserter, s []T, elem T) {
+	is := asserterOf(a)
+	is.tb.Helper()
+	for i, v := range s {
+		equal, err := comparableEqual(v, elem, is.comparers)
+		if err != nil {
+			is.failUncomparable(v, elem, err)
+			return
+		}
+		if equal {
+			fail(is, "expected '%v' (%s) not to contain '%v' (%s), but it was found at index %d",
+				s, objectTypeName(s),
+				elem, objectTypeName(elem), i)
+			return
+		}
+	}
+}
+
+// ElementsMatch fails if the provided slices do not contain the same
+// elements, ignoring their order. Duplicate elements must appear the same
+// number of times in both slices. Elements are compared in the same way as
+// Equal.
+func ElementsMatch[T comparable](a Asserter, actual []T, expected []T) {
+	is := asserterOf(a)
+	is.tb.Helper()
+	aList := make([]interface{}, len(actual))
+	for i, v := range actual {
+		aList[i] = v
+	}
+	eList := make([]interface{}, len(expected))
+	for i, v := range expected {
+		eList[i] = v
+	}
+	var uncomparable error
+	var ua, ue interface{}
+	equal := func(a, b interface{}) bool {
+		if uncomparable != nil {
+			return false
+		}
+		same, err := comparableEqual(a.(T), b.(T), is.comparers)
+		if err != nil {
+			uncomparable, ua, ue = err, a, b
+		}
+		return same
+	}
+	d := elementsDiff(aList, eList, equal)
+	if uncomparable != nil {
+		is.failUncomparable(ua, ue, uncomparable)
+		return
+	}
+	if d != "" {
+		fail(is, "actual elements '%v' (%s) should match expected elements '%v' (%s) - Differences:\n%s",
+			actual, objectTypeName(actual),
+			expected, objectTypeName(expected),
+			d,
+		)
+	}
+}
+
+// Must fails if err is not nil, and otherwise returns v. It allows the
+// result of a function returning a value and an error to be used directly:
+//
+// user := is.Must(assert, db.GetUser(id))
+func Must[T any](a Asserter, v T, err error) T {
+	is := asserterOf(a)
+	is.tb.Helper()
+	if !isNil(err) {
+		fail(is, "expected no error, but got: %v", err)
+	}
+	return v
+}
+
+// ErrAs finds the first error in the chain of err that can be assigned to a
+// value of type T, using errors.As, and returns it. It fails if there is no
+// such error. T must be an interface type or implement error.
+//
+// pathErr := is.ErrAs[*fs.PathError](assert, err)
+func ErrAs[T any](a Asserter, err error) T {
+	is := asserterOf(a)
+	is.tb.Helper()
+	var target T
+	is.ErrAs(err, &target)
+	return target
+}
@@ -0,0 +1,141 @@
+package is
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type genericUser struct {
+	ID   int
+	Name string
+}
+
+func TestGeneric(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	Equal(assert, 1, 1)
+	Equal(assert, genericUser{1, "a"}, genericUser{1, "a"})
+	NotEqual(assert, "a", "b")
+	DeepEqual(assert, []int{1, 2}, []int{1, 2})
+	Contains(assert, []string{"a", "b"}, "b")
+	NotContains(assert, []string{"a", "b"}, "c")
+	ElementsMatch(assert, []int{1, 2, 2, 3}, []int{2, 3, 1, 2})
+	if v := Must(assert, 42, nil); v != 42 {
+		t.Fatalf("expected Must to return 42, but got %d", v)
+	}
+	if hit != 0 {
+		t.Fatalf("expected no failures, but got: %s", msg)
+	}
+
+	Equal(assert, genericUser{1, "a"}, genericUser{1, "b"})
+	if hit != 1 || !strings.Contains(msg, `.Name: "a" != "b"`) {
+		t.Fatalf("expected Equal failure with diff, but got: %s", msg)
+	}
+
+	NotEqual(assert, 1, 1)
+	DeepEqual(assert, map[string]int{"a": 1}, map[string]int{"a": 2})
+	Contains(assert, []int{1, 2}, 3)
+	if hit != 4 {
+		t.Fatalf("expected 4 failures, but got %d", hit)
+	}
+
+	NotContains(assert, []int{1, 2}, 2)
+	if hit != 5 || !strings.Contains(msg, "found at index 1") {
+		t.Fatalf("expected NotContains failure, but got: %s", msg)
+	}
+
+	ElementsMatch(assert, []int{1, 2, 2, 4}, []int{1, 1, 2, 3})
+	if hit != 6 || !strings.HasSuffix(msg, "\tmissing: 1 (expected 2, found 1)\n\tmissing: 3\n\textra: 2 (expected 1, found 2)\n\textra: 4\n") {
+		t.Fatalf("expected ElementsMatch failure, but got: %s", msg)
+	}
+
+	Must(assert, 0, errors.New("boom"))
+	if hit != 7 || msg != "expected no error, but got: boom" {
+		t.Fatalf("expected Must failure, but got: %s", msg)
+	}
+
+	now := time.Now()
+	Equal(assert, now, now.In(time.FixedZone("X", 3600)))
+	Equal(assert, &equaler{equal: true}, &equaler{equal: true})
+	Equal(assert.WithComparer(func(a, b genericUser) bool { return a.ID == b.ID }), genericUser{1, "a"}, genericUser{1, "b"})
+	NotEqual(assert, now, now.Add(time.Second))
+	Equal[any](assert, 1, 1)
+	if hit != 7 {
+		t.Fatalf("expected equality methods and comparers to be used, but got: %s", msg)
+	}
+
+	Equal[any](assert, []int{1}, []int{1})
+	if hit != 8 || msg != "unable to compare actual value '[1]' ([]int) to expected value '[1]' ([]int): runtime error: comparing uncomparable type []int" {
+		t.Fatalf("expected failure for uncomparable values, but got: %s", msg)
+	}
+
+	NotEqual[any](assert, map[int]int{}, map[int]int{})
+	if hit != 9 || !strings.HasPrefix(msg, "unable to compare actual value") {
+		t.Fatalf("expected failure for uncomparable values, but got: %s", msg)
+	}
+
+	Contains(assert, []time.Time{now}, now.In(time.FixedZone("X", 3600)))
+	NotContains(assert, []time.Time{now}, now.Add(time.Second))
+	ElementsMatch(assert, []time.Time{now, now.Add(time.Second)}, []time.Time{now.Add(time.Second), now.In(time.FixedZone("X", 3600))})
+	ElementsMatch(assert.WithComparer(func(a, b genericUser) bool { return a.ID == b.ID }), []genericUser{{1, "a"}}, []genericUser{{1, "b"}})
+	if hit != 9 {
+		t.Fatalf("expected equality methods and comparers to be used, but got: %s", msg)
+	}
+
+	ElementsMatch[any](assert, []any{[]int{1}}, []any{[]int{1}})
+	if hit != 10 || msg != "unable to compare actual value '[1]' ([]int) to expected value '[1]' ([]int): runtime error: comparing uncomparable type []int" {
+		t.Fatalf("expected failure for uncomparable elements, but got: %s", msg)
+	}
+	Contains[any](assert, []any{1, []int{1}}, []int{1})
+	NotContains[any](assert, []any{[]int{1}}, []int{2})
+	if hit != 12 || !strings.HasPrefix(msg, "unable to compare actual value") {
+		t.Fatalf("expected failure for uncomparable elements, but got: %s", msg)
+	}
+
+	msgFormat := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		msgFormat = is.failFormat
+	}
+	Equal(assert.Msg("user %d", 1), 1, 2)
+	if msgFormat != "user %d" {
+		t.Fatalf("expected Msg to be used, but got: %q", msgFormat)
+	}
+
+	fail = failDefault
+}
+
+func TestGenericLax(t *testing.T) {
+	assert := New(t)
+
+	hitLax := 0
+	hitStrict := 0
+	fail = func(is *asserter, format string, args ...interface{}) {
+		if is.strict {
+			hitStrict++
+			return
+		}
+		is.failed = true
+		hitLax++
+	}
+
+	assert.Lax(func(lax Asserter) {
+		Equal(lax, 1, 2)
+		lax.Equal(1, 2)
+		Contains(lax, []int{1}, 2)
+	})
+
+	fail = failDefault
+
+	Equal(assert, hitLax, 3)
+	Equal(assert, hitStrict, 1)
+}
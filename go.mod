@@ -1,5 +1,7 @@
 module github.com/tylerb/is/v3
 
-go 1.13
+go 1.20
 
 require github.com/google/go-cmp v0.4.0
+
+require golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543 // indirect
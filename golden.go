@@ -0,0 +1,124 @@
+package is
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// update is set by running tests with -is.update, and causes golden files to
+// be rewritten with the actual values instead of being compared.
+var update = flag.Bool("is.update", false, "update golden files with the actual values")
+
+// updateEnv is the environment variable which, when set to a true value,
+// has the same effect as -is.update.
+const updateEnv = "IS_UPDATE"
+
+// goldenDir is the directory in which golden files are stored. It is
+// overridden in test code for this package.
+var goldenDir = "testdata"
+
+// updating reports whether golden files should be rewritten.
+func updating() bool {
+	if *update {
+		return true
+	}
+	v, _ := strconv.ParseBool(os.Getenv(updateEnv))
+	return v
+}
+
+// goldenPath returns the path of the golden file called name for the test
+// called testName. Subtests are stored in nested directories.
+func goldenPath(testName string, name string) string {
+	parts := strings.Split(testName, "/")
+	for i, p := range parts {
+		parts[i] = sanitizePathElement(p)
+	}
+	parts = append(parts, sanitizePathElement(name)+".golden")
+	return filepath.Join(append([]string{goldenDir}, parts...)...)
+}
+
+// sanitizePathElement replaces characters that are not valid in file names
+// on common platforms.
+func sanitizePathElement(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '<', '>', ':', '"', '/', '\\', '|', '?', '*':
+			return '_'
+		}
+		if r < ' ' {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// golden compares actual to the contents of the golden file called name, or
+// rewrites the file if tests are being run in update mode.
+func (self *asserter) golden(name string, actual []byte) {
+	self.tb.Helper()
+	path := goldenPath(self.tb.Name(), name)
+
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			fail(self, "unable to create directory for golden file %s: %v", path, err)
+			return
+		}
+		if err := os.WriteFile(path, actual, 0644); err != nil {
+			fail(self, "unable to write golden file %s: %v", path, err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		fail(self, "golden file %s does not exist; run tests with -is.update or %s=1 to create it", path, updateEnv)
+		return
+	}
+	if err != nil {
+		fail(self, "unable to read golden file %s: %v", path, err)
+		return
+	}
+	if bytes.Equal(actual, expected) {
+		return
+	}
+
+	if !utf8.Valid(actual) || !utf8.Valid(expected) {
+		fail(self, "actual value does not match golden file %s; run tests with -is.update or %s=1 to update it",
+			path, updateEnv)
+		return
+	}
+	d := unifiedDiff(string(actual), string(expected), diffContext)
+	if d == "" {
+		fail(self, "actual value %q does not match golden file %s containing %q; run tests with -is.update or %s=1 to update it",
+			actual, path, expected, updateEnv)
+		return
+	}
+	fail(self, "actual value does not match golden file %s; run tests with -is.update or %s=1 to update it - Diff:\n%s",
+		path, updateEnv, d)
+}
+
+func (self *asserter) Golden(name string, actual []byte) {
+	self.tb.Helper()
+	self.golden(name, actual)
+}
+
+func (self *asserter) GoldenString(name string, actual string) {
+	self.tb.Helper()
+	self.golden(name, []byte(actual))
+}
+
+func (self *asserter) GoldenJSON(name string, actual interface{}) {
+	self.tb.Helper()
+	b, err := json.MarshalIndent(actual, "", "  ")
+	if err != nil {
+		fail(self, "unable to marshal object '%s' to JSON: %v", objectTypeName(actual), err)
+		return
+	}
+	self.golden(name, append(b, '\n'))
+}
@@ -0,0 +1,82 @@
+package is
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGolden(t *testing.T) {
+	assert := New(t)
+
+	dir, err := os.MkdirTemp("", "is-golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	goldenDir = dir
+	defer func() { goldenDir = "testdata" }()
+
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	assert.GoldenString("missing", "value")
+	if !strings.Contains(msg, "does not exist; run tests with -is.update") {
+		t.Fatalf("expected missing golden file failure, but got: %s", msg)
+	}
+
+	os.Setenv(updateEnv, "1")
+	msg = ""
+	assert.GoldenString("text", "a\nb\nc\n")
+	assert.Golden("bytes", []byte{0, 1, 2})
+	assert.GoldenJSON("json", map[string]int{"b": 2, "a": 1})
+	os.Unsetenv(updateEnv)
+	if msg != "" {
+		t.Fatalf("expected no failure in update mode, but got: %s", msg)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "TestGolden", "json.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "{\n  \"a\": 1,\n  \"b\": 2\n}\n" {
+		t.Fatalf("unexpected golden file contents: %q", b)
+	}
+
+	assert.GoldenString("text", "a\nb\nc\n")
+	assert.Golden("bytes", []byte{0, 1, 2})
+	assert.GoldenJSON("json", map[string]int{"a": 1, "b": 2})
+	if msg != "" {
+		t.Fatalf("expected no failure, but got: %s", msg)
+	}
+
+	assert.GoldenString("text", "a\nx\nc\n")
+	if !strings.Contains(msg, " a\n-x\n+b\n c\n") {
+		t.Fatalf("expected unified diff, but got: %s", msg)
+	}
+
+	msg = ""
+	assert.GoldenString("text", "a\nb\nc")
+	if !strings.Contains(msg, `"a\nb\nc" does not match`) {
+		t.Fatalf("expected quoted values for a trailing newline difference, but got: %s", msg)
+	}
+
+	msg = ""
+	assert.Golden("bytes", []byte{0, 1, 0xff})
+	if !strings.HasPrefix(msg, "actual value does not match golden file") {
+		t.Fatalf("expected binary mismatch failure, but got: %s", msg)
+	}
+
+	t.Run("sub test", func(t *testing.T) {
+		path := goldenPath(t.Name(), "a:b")
+		if path != filepath.Join(dir, "TestGolden", "sub_test", "a_b.golden") {
+			t.Fatalf("unexpected golden path: %s", path)
+		}
+	})
+
+	fail = failDefault
+}
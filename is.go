@@ -1,11 +1,18 @@
 package is
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 // Equaler is used to define equality for types.
@@ -14,6 +21,9 @@ import (
 // fields. You can implement this method and use time.Time.Equal() to do the
 // comparison.
 //
+// Equal is called on both values being compared, at every level of nesting,
+// so the order of the arguments does not change the result.
+//
 // Deprecated
 type Equaler interface {
 	Equal(in interface{}) bool
@@ -24,6 +34,11 @@ type Equaler interface {
 // For example, this is useful if you have a struct that includes time.Time
 // fields. You can implement this method and use time.Time.Equal() to do the
 // comparison.
+//
+// IsEqual is called on both values being compared, at every level of nesting,
+// so the order of the arguments does not change the result. Types with a
+// method of the form Equal(T) bool, such as time.Time and net.IP, are
+// compared with that method in the same way.
 type EqualityChecker interface {
 	IsEqual(in interface{}) bool
 }
@@ -54,12 +69,34 @@ type Asserter interface {
 	// assert.AddMsg("Raw Response: %s",body).Equal(res.StatusCode, http.StatusCreated)
 	AddMsg(format string, args ...interface{}) Asserter
 
+	// WithComparer returns an Asserter that uses fn, which must be a function
+	// of the form func(a, b T) bool, to decide whether two values of type T
+	// are equal. It is consulted wherever values are compared deeply, at
+	// every level of nesting, so it also applies to values of type T inside
+	// slices, maps and struct fields. If T is an interface type, fn is used
+	// for any pair of non-nil values whose dynamic types both implement T,
+	// even if those types differ.
+	//
+	// This allows equality to be defined for types you do not own, such as
+	// time.Time or big.Int:
+	//
+	// assert := is.New(t).WithComparer(func(a, b time.Time) bool { return a.Equal(b) })
+	//
+	// Comparers added with WithComparer take precedence over those registered
+	// with RegisterComparer. A comparer for the exact type is preferred over
+	// one for an interface, and later comparers take precedence over earlier
+	// ones. WithComparer panics if fn is not a valid comparer.
+	WithComparer(fn interface{}) Asserter
+
 	// Equal performs a deep compare of the provided objects and fails if they are
 	// not equal.
 	//
 	// Equal does not respect type differences. If the types are different and
 	// comparable (eg int32 and int64), they will be compared as though they are
 	// the same type.
+	//
+	// If the Asserter was created with the Strict option, Equal behaves like
+	// EqualStrict.
 	Equal(actual interface{}, expected interface{})
 
 	// NotEqual performs a deep compare of the provided objects and fails if they are
@@ -68,8 +105,117 @@ type Asserter interface {
 	// NotEqual does not respect type differences. If the types are different and
 	// comparable (eg int32 and int64), they will be compared as though they are
 	// the same type.
+	//
+	// If the Asserter was created with the Strict option, values of different
+	// types are never equal.
 	NotEqual(a interface{}, b interface{})
 
+	// EqualStrict performs a deep compare of the provided objects and fails if
+	// they are not equal. Unlike Equal, it requires both objects to have
+	// identical dynamic types and never converts one to the type of the
+	// other.
+	EqualStrict(actual interface{}, expected interface{})
+
+	// EqualNumeric performs a deep compare of the provided objects and fails
+	// if any pair of corresponding numbers is not exactly equal in value.
+	// Signed, unsigned, floating point and complex numbers of any size are
+	// compared without overflow, truncation or rounding, so int8(44) is not
+	// equal to int64(300), but uint64(1<<63) is equal to float64(1<<63). All
+	// other values must be equal.
+	EqualNumeric(actual interface{}, expected interface{})
+
+	// EqualOpts compares the provided objects using cmp.Equal with the
+	// provided options and fails if they are not equal. Any options provided
+	// to New via CmpOptions are applied before the provided options.
+	//
+	// This allows equality to be customized with options such as
+	// cmpopts.IgnoreFields, cmpopts.EquateApprox or cmp.Comparer. On
+	// failure, the output of cmp.Diff is printed.
+	EqualOpts(actual interface{}, expected interface{}, opts ...cmp.Option)
+
+	// NotEqualOpts compares the provided objects using cmp.Equal with the
+	// provided options and fails if they are equal. Any options provided to
+	// New via CmpOptions are applied before the provided options.
+	NotEqualOpts(actual interface{}, expected interface{}, opts ...cmp.Option)
+
+	// InDelta performs a deep compare of the provided objects and fails if
+	// any pair of corresponding numbers differs by more than delta. Numbers of
+	// any kind, including complex, may be compared, both at the top level and
+	// within slices, arrays, maps and struct fields. All other values must be
+	// equal.
+	InDelta(actual interface{}, expected interface{}, delta float64)
+
+	// InEpsilon performs a deep compare of the provided objects and fails if
+	// the relative error, |actual-expected|/|expected|, of any pair of
+	// corresponding numbers is greater than epsilon. Numbers are found in the
+	// same way as InDelta.
+	InEpsilon(actual interface{}, expected interface{}, epsilon float64)
+
+	// InULP performs a deep compare of the provided objects and fails if any
+	// pair of corresponding numbers is more than ulps units in the last place
+	// apart. Numbers are found in the same way as InDelta. If both numbers are
+	// float32 or complex64, the distance is measured in float32 steps;
+	// otherwise it is measured in float64 steps.
+	InULP(actual interface{}, expected interface{}, ulps uint64)
+
+	// ElementsMatch compares the provided slices or arrays as multisets and
+	// fails if they do not contain the same elements, ignoring their order.
+	// Elements are compared in the same way as Equal, and duplicate elements
+	// must appear the same number of times in both objects.
+	//
+	// On failure, every element that is missing from actual or extra in
+	// actual is printed, along with how many times it was expected and found
+	// if the difference is in the number of duplicates.
+	ElementsMatch(actual interface{}, expected interface{})
+
+	// Subset performs a deep compare of the provided objects, ignoring any
+	// struct field that is the zero value in expected and any map key that is
+	// not present in expected. It fails if any remaining value differs. This
+	// allows large values to be checked without enumerating every field.
+	//
+	// Nested structs and maps are compared in the same way. Slices and arrays
+	// are compared element by element, and must have the same length.
+	Subset(actual interface{}, expected interface{})
+
+	// JSONEq decodes the provided objects as JSON and fails if they are not
+	// semantically equal. Object key order and whitespace are ignored, and
+	// numbers are compared by their exact value, so 1, 1.0 and 1e0 are equal.
+	//
+	// Each object may be a []byte, string or io.Reader containing JSON text.
+	// Any other value is marshaled with encoding/json first. On failure, the
+	// RFC 6901 JSON pointer of each difference is printed.
+	JSONEq(actual interface{}, expected interface{})
+
+	// Golden compares actual to the contents of the golden file
+	// testdata/<test name>/<name>.golden and fails if they differ, printing a
+	// unified diff. Subtests are stored in nested directories.
+	//
+	// When tests are run with the -is.update flag, or with the IS_UPDATE
+	// environment variable set to a true value, the golden file is created or
+	// rewritten with actual instead.
+	Golden(name string, actual []byte)
+
+	// GoldenString behaves like Golden, but accepts a string.
+	GoldenString(name string, actual string)
+
+	// GoldenJSON behaves like Golden, but compares the indented JSON encoding
+	// of actual.
+	GoldenJSON(name string, actual interface{})
+
+	// Snapshot compares a pretty-printed representation of actual to the
+	// snapshot provided with the Inline option, and fails if they differ.
+	// Strings are compared as is. Leading and trailing whitespace is ignored.
+	//
+	// When tests are run with the -is.update flag, or with the IS_UPDATE
+	// environment variable set to a true value, the call to Snapshot in the
+	// test source is rewritten to store the actual value in its Inline
+	// option instead:
+	//
+	// assert.Snapshot(user, is.Inline(`&app.User{
+	// 	Name: "bob",
+	// }`))
+	Snapshot(actual interface{}, opts ...SnapshotOption)
+
 	// OneOf performs a deep compare of the provided object and an array of
 	// comparison objects. It fails if the first object is not equal to one of the
 	// comparison objects.
@@ -88,6 +234,89 @@ type Asserter interface {
 	// the same type.
 	NotOneOf(a interface{}, b ...interface{})
 
+	// Contains checks that the provided container contains elem. Strings are
+	// searched for a substring, slices and arrays for an element, and maps
+	// for a key. Elements and keys are compared in the same way as Equal.
+	//
+	// If the container is not one of string, array, slice or map, it will
+	// fail.
+	Contains(container interface{}, elem interface{})
+
+	// NotContains checks that the provided container does not contain elem,
+	// using the same rules as Contains.
+	NotContains(container interface{}, elem interface{})
+
+	// Greater checks that a is greater than b. Numbers of any real type are
+	// compared by their exact values, strings lexically, and values of other
+	// types, such as time.Time, with a method of the form Compare(T) int.
+	Greater(a interface{}, b interface{})
+
+	// GreaterOrEqual checks that a is greater than or equal to b, using the
+	// same rules as Greater.
+	GreaterOrEqual(a interface{}, b interface{})
+
+	// Less checks that a is less than b, using the same rules as Greater.
+	Less(a interface{}, b interface{})
+
+	// LessOrEqual checks that a is less than or equal to b, using the same
+	// rules as Greater.
+	LessOrEqual(a interface{}, b interface{})
+
+	// Between checks that v is greater than or equal to lo and less than or
+	// equal to hi, using the same rules as Greater. It fails if lo is greater
+	// than hi.
+	Between(v interface{}, lo interface{}, hi interface{})
+
+	// Sorted checks that the elements of the provided array or slice are in
+	// ascending order, using the same rules as Greater. On failure, the first
+	// pair of elements found out of order is printed.
+	Sorted(list interface{})
+
+	// SortedFunc checks that the elements of the provided array or slice are
+	// sorted according to less, which must be a function of the form
+	// func(a, b T) bool reporting whether a sorts before b.
+	SortedFunc(list interface{}, less interface{})
+
+	// Match checks that s matches the regular expression pattern. s may be a
+	// string, a []byte or a fmt.Stringer.
+	Match(pattern string, s interface{})
+
+	// NotMatch checks that s does not match the regular expression pattern.
+	// On failure, the position of the first match is printed.
+	NotMatch(pattern string, s interface{})
+
+	// HasPrefix checks that s begins with prefix. s may be a string, a []byte
+	// or a fmt.Stringer. On failure, the position of the first difference is
+	// printed.
+	HasPrefix(s interface{}, prefix string)
+
+	// HasSuffix checks that s ends with suffix. s may be a string, a []byte or
+	// a fmt.Stringer. On failure, the position of the last difference is
+	// printed.
+	HasSuffix(s interface{}, suffix string)
+
+	// EqualFold checks that the provided values are equal under Unicode case
+	// folding. Both may be a string, a []byte or a fmt.Stringer. On failure,
+	// the position of the first difference is printed.
+	EqualFold(actual interface{}, expected interface{})
+
+	// EqualIgnoreSpace checks that the provided values are equal once every
+	// run of white space is replaced with a single space and leading and
+	// trailing white space is removed. Both may be a string, a []byte or a
+	// fmt.Stringer.
+	EqualIgnoreSpace(actual interface{}, expected interface{})
+
+	// EqualIgnoreLineEndings checks that the provided values are equal once
+	// \r\n and \r line endings are replaced with \n. Both may be a string, a
+	// []byte or a fmt.Stringer.
+	EqualIgnoreLineEndings(actual interface{}, expected interface{})
+
+	// ReaderEqual reads both readers to the end and checks that they produce
+	// the same bytes. The readers are compared a chunk at a time, so large
+	// payloads are not held in memory. On failure, hex dumps of both are
+	// printed around the first difference.
+	ReaderEqual(actual io.Reader, expected io.Reader)
+
 	// Err checks the provided error object to determine if an error is present.
 	Err(e error)
 
@@ -95,9 +324,41 @@ type Asserter interface {
 	// present.
 	NotErr(e error)
 
-	// Nil checks the provided object to determine if it is nil.
+	// ErrIs checks that target is found in the chain of the provided error,
+	// using errors.Is. On failure, the full chain of wrapped errors is
+	// printed, including those joined with errors.Join.
+	ErrIs(err error, target error)
+
+	// ErrAs checks that an error in the chain of the provided error can be
+	// assigned to target, which must be a non-nil pointer to a type
+	// implementing error or to an interface type, using errors.As. It sets
+	// target to that error and returns it. On failure, the full chain of
+	// wrapped errors is printed and nil is returned.
+	ErrAs(err error, target interface{}) interface{}
+
+	// ErrContains checks that the message of the provided error contains
+	// substr. On failure, the full chain of wrapped errors is printed.
+	ErrContains(err error, substr string)
+
+	// ErrMatches checks that the message of the provided error matches the
+	// regular expression pattern. On failure, the full chain of wrapped
+	// errors is printed.
+	ErrMatches(err error, pattern string)
+
+	// Nil checks the provided object to determine if it is nil. Both an
+	// untyped nil and a nil pointer, slice, map, chan or func held in an
+	// interface are accepted.
 	Nil(o interface{})
 
+	// NilInterface checks that the provided object is an untyped nil, as an
+	// interface such as error holding no value would be. An interface holding
+	// a nil pointer is not a nil interface, and fails this assertion.
+	NilInterface(o interface{})
+
+	// TypedNil checks that the provided object is a nil pointer, slice, map,
+	// chan or func held in a non-nil interface.
+	TypedNil(o interface{})
+
 	// NotNil checks the provided object to determine if it is not nil.
 	NotNil(o interface{})
 
@@ -115,40 +376,150 @@ type Asserter interface {
 	// an array is empty or a map is empty. It could also be used to determine if
 	// a number is 0.
 	//
-	// In cases such as slice, map, array and chan, a nil value is treated the
-	// same as an object with len == 0
+	// In cases such as slice, map and chan, a nil value is treated the same as
+	// an object with len == 0. A pointer is zero if it is nil or points to a
+	// zero value, and an array is zero if all its elements are zero values.
+	// Objects with an IsZero() bool method, such as time.Time, are zero if the
+	// method returns true. On failure, the rule that was applied is printed.
+	//
+	// ZeroValue and Empty check only the zero value or only the length.
 	Zero(o interface{})
 
 	// NotZero checks the provided object to determine if it is not the zero
-	// value for the type of that object. The zero value is the same as what the
-	// object would contain when initialized but not assigned.
+	// value for the type of that object, using the same rules as Zero.
 	//
 	// This method, for example, would be used to determine if a string is not
 	// empty, an array is not empty or a map is not empty. It could also be used
 	// to determine if a number is not 0.
-	//
-	// In cases such as slice, map, array and chan, a nil value is treated the
-	// same as an object with len == 0
 	NotZero(o interface{})
 
+	// ZeroValue checks that the provided object is the zero value of its type,
+	// as reported by reflect.Value.IsZero. Unlike Zero, an empty but non-nil
+	// slice or map, and a non-nil pointer, are not zero values, and IsZero
+	// methods are not consulted.
+	ZeroValue(o interface{})
+
+	// NotZeroValue checks that the provided object is not the zero value of
+	// its type, using the same rules as ZeroValue.
+	NotZeroValue(o interface{})
+
 	// Len checks the provided object to determine if it is the same length as the
 	// provided length argument.
 	//
-	// If the object is not one of type array, slice or map, it will fail.
+	// If the object is not one of type array, pointer to array, chan, map, slice
+	// or string, and has no Len() int method, it will fail.
 	Len(o interface{}, l int)
 
+	// Cap checks the provided object to determine if it has the provided
+	// capacity.
+	//
+	// If the object is not one of type array, pointer to array, chan or slice,
+	// and has no Cap() int method, it will fail.
+	Cap(o interface{}, c int)
+
+	// Empty checks the provided object to determine if its length is zero,
+	// using the same rules as Len. A nil object is treated as empty.
+	Empty(o interface{})
+
+	// NotEmpty checks the provided object to determine if its length is not
+	// zero, using the same rules as Len.
+	NotEmpty(o interface{})
+
 	// ShouldPanic expects the provided function to panic. If the function does
 	// not panic, this assertion fails.
 	ShouldPanic(f func())
 
+	// PanicsWith expects the provided function to panic with a value equal to
+	// expected, compared in the same way as Equal. If the function does not
+	// panic, or panics with a different value, this assertion fails.
+	PanicsWith(expected interface{}, f func())
+
+	// PanicsWithError expects the provided function to panic with an error
+	// whose chain contains target, using errors.Is. If the function does not
+	// panic, or panics with a different value, this assertion fails.
+	PanicsWithError(target error, f func())
+
+	// PanicMatches expects the provided function to panic with a value whose
+	// string representation matches the regular expression pattern. If the
+	// function does not panic, or panics with a different value, this
+	// assertion fails.
+	PanicMatches(pattern string, f func())
+
+	// NotPanics expects the provided function not to panic. If it does, the
+	// panic is recovered and this assertion fails, printing the panic value
+	// and the stack of the panicking goroutine.
+	NotPanics(f func())
+
+	// PanicValue expects the provided function to panic, and returns the
+	// recovered value along with the stack of the panicking goroutine, so that
+	// further assertions can be made on them. If the function does not panic,
+	// this assertion fails and nil values are returned.
+	PanicValue(f func()) (recovered interface{}, stack []byte)
+
 	// EqualType checks the type of the two provided objects and
 	// fails if they are not the same.
 	EqualType(expected, actual interface{})
 
+	// Implements checks that the type of o implements the interface type
+	// pointed to by iface, which is given as a nil pointer, for example
+	// (*io.Reader)(nil).
+	Implements(iface interface{}, o interface{})
+
+	// AssignableTo checks that a value of the type of o can be assigned to the
+	// type pointed to by target, which is given as a nil pointer, for example
+	// (*io.Reader)(nil). Like Implements, it takes the target type first.
+	AssignableTo(target interface{}, o interface{})
+
+	// ConvertibleTo checks that a value of the type of o can be converted to
+	// the type pointed to by target, which is given as a nil pointer, for
+	// example (*int64)(nil). Like Implements, it takes the target type first.
+	ConvertibleTo(target interface{}, o interface{})
+
+	// Kind checks that o is of the provided kind.
+	Kind(o interface{}, kind reflect.Kind)
+
+	// Same checks that a and b are of the same type and point to the same
+	// location. Both must be one of pointer, chan, map or unsafe.Pointer.
+	// Functions are rejected, since their pointers identify only their code,
+	// which distinct closures may share.
+	Same(a interface{}, b interface{})
+
+	// NotSame checks that a and b do not point to the same location, using the
+	// same rules as Same.
+	NotSame(a interface{}, b interface{})
+
 	// WaitForTrue waits until the provided func returns true. If the timeout is
 	// reached before the function returns true, the test will fail.
+	//
+	// WaitForTrue is equivalent to Eventually with a poll interval of 100ms.
 	WaitForTrue(timeout time.Duration, f func() bool)
 
+	// WaitForTrueCtx waits until the provided func returns true, polling every
+	// 100ms. If the context is done before the function returns true, the
+	// test will fail.
+	WaitForTrueCtx(ctx context.Context, f func() bool)
+
+	// Eventually calls the provided func every poll interval until it returns
+	// true. If the timeout is reached before the function returns true, the
+	// test will fail. The interval may be varied with Backoff and Jitter. The
+	// poll interval must be positive.
+	Eventually(timeout time.Duration, poll time.Duration, f func() bool, opts ...PollOption)
+
+	// Consistently calls the provided func every poll interval for the
+	// provided duration, and fails if it ever returns false.
+	Consistently(duration time.Duration, poll time.Duration, f func() bool, opts ...PollOption)
+
+	// Never calls the provided func every poll interval for the provided
+	// duration, and fails if it ever returns true.
+	Never(duration time.Duration, poll time.Duration, f func() bool, opts ...PollOption)
+
+	// EventuallyWith calls the provided func every poll interval, passing it
+	// an Asserter that collects failures rather than reporting them, until a
+	// call completes without any assertion failing. A failing assertion ends
+	// that call. If the timeout is reached first, the test will fail, printing
+	// the failures from the last call.
+	EventuallyWith(timeout time.Duration, poll time.Duration, f func(a Asserter), opts ...PollOption)
+
 	// Lax accepts a function inside which a failed assertion will not halt
 	// test execution. After the function returns, if any assertion had failed,
 	// an additional message will be printed and test execution will be halted.
@@ -165,16 +536,68 @@ type asserter struct {
 	failFormat string
 	failArgs   []interface{}
 	failed     bool
+	cmpOpts    []cmp.Option
+
+	// strictTypes is set by the Strict option. It is unrelated to strict,
+	// which controls whether failures halt the test.
+	strictTypes bool
+
+	comparers *comparers
+
+	// context is the number of unchanged lines printed around each change
+	// when multi-line strings are compared.
+	context int
 }
 
 var _ Asserter = (*asserter)(nil)
 
-// New returns a new Asserter containing the testing object provided.
-func New(tb testing.TB) Asserter {
+// Option configures an Asserter returned by New.
+type Option func(is *asserter)
+
+// CmpOptions sets default go-cmp options for the Asserter. When any are set,
+// Equal and NotEqual first compare using their usual rules, including type
+// conversion, Equaler, EqualityChecker and comparers, and only if those find
+// the values unequal do they compare using cmp.Equal with these options. The
+// options can therefore make values equal, but never unequal. EqualOpts and
+// NotEqualOpts apply them before their own options.
+func CmpOptions(opts ...cmp.Option) Option {
+	return func(is *asserter) {
+		is.cmpOpts = append(is.cmpOpts, opts...)
+	}
+}
+
+// Strict makes Equal, NotEqual, OneOf and NotOneOf require the compared
+// objects to have identical dynamic types, rather than converting one to the
+// type of the other.
+func Strict() Option {
+	return func(is *asserter) {
+		is.strictTypes = true
+	}
+}
+
+// DiffContext sets the number of unchanged lines printed around each change
+// when Equal fails on multi-line strings. The default is 3, and negative
+// values are treated as 0.
+func DiffContext(lines int) Option {
+	return func(is *asserter) {
+		if lines < 0 {
+			lines = 0
+		}
+		is.context = lines
+	}
+}
+
+// New returns a new Asserter containing the testing object provided,
+// configured with the provided options.
+func New(tb testing.TB, opts ...Option) Asserter {
 	if tb == nil {
 		log.Fatalln("You must provide a testing object.")
 	}
-	return &asserter{tb: tb, strict: true}
+	is := &asserter{tb: tb, strict: true, context: diffContext}
+	for _, opt := range opts {
+		opt(is)
+	}
+	return is
 }
 
 func (self *asserter) TB() testing.TB {
@@ -185,10 +608,14 @@ func (self *asserter) TB() testing.TB {
 // to print out additional information about a failure if it happens.
 func (self *asserter) Msg(format string, args ...interface{}) Asserter {
 	return &asserter{
-		tb:         self.tb,
-		strict:     self.strict,
-		failFormat: format,
-		failArgs:   args,
+		tb:          self.tb,
+		strict:      self.strict,
+		failFormat:  format,
+		failArgs:    args,
+		cmpOpts:     self.cmpOpts,
+		strictTypes: self.strictTypes,
+		comparers:   self.comparers,
+		context:     self.context,
 	}
 }
 
@@ -197,38 +624,285 @@ func (self *asserter) AddMsg(format string, args ...interface{}) Asserter {
 		return self.Msg(format, args...)
 	}
 	return &asserter{
-		tb:         self.tb,
-		strict:     self.strict,
-		failFormat: fmt.Sprintf("%s - %s", self.failFormat, format),
-		failArgs:   append(self.failArgs, args...),
+		tb:          self.tb,
+		strict:      self.strict,
+		failFormat:  fmt.Sprintf("%s - %s", self.failFormat, format),
+		failArgs:    append(self.failArgs, args...),
+		cmpOpts:     self.cmpOpts,
+		strictTypes: self.strictTypes,
+		comparers:   self.comparers,
+		context:     self.context,
+	}
+}
+
+func (self *asserter) WithComparer(fn interface{}) Asserter {
+	return &asserter{
+		tb:          self.tb,
+		strict:      self.strict,
+		failFormat:  self.failFormat,
+		failArgs:    self.failArgs,
+		cmpOpts:     self.cmpOpts,
+		strictTypes: self.strictTypes,
+		comparers:   self.comparers.with(fn),
+		context:     self.context,
 	}
 }
 
 func (self *asserter) Equal(actual interface{}, expected interface{}) {
 	self.tb.Helper()
-	if !isEqual(actual, expected) {
+	if len(self.cmpOpts) > 0 {
+		// The usual equality rules apply first, so that CmpOptions only
+		// relaxes the comparison.
+		if !self.isEqual(actual, expected) {
+			self.EqualOpts(actual, expected)
+		}
+		return
+	}
+	if self.strictTypes {
+		self.EqualStrict(actual, expected)
+		return
+	}
+	if !isEqual(actual, expected, self.comparers) {
+		self.failNotEqual(actual, expected)
+	}
+}
+
+// failNotEqual fails with a description of how actual differs from
+// expected. Multi-line strings are shown as a line-based diff, and byte
+// slices as hex dumps, rather than printed in full.
+func (self *asserter) failNotEqual(actual interface{}, expected interface{}) {
+	self.tb.Helper()
+	a, e := reflect.ValueOf(actual), reflect.ValueOf(expected)
+	if a.Kind() == reflect.String && e.Kind() == reflect.String &&
+		(strings.Contains(a.String(), "\n") || strings.Contains(e.String(), "\n")) {
+		if d := textDiff(a.String(), e.String(), self.context); d != "" {
+			fail(self, "actual value (%s) should be equal to expected value (%s) - Diff:\n%s",
+				objectTypeName(actual), objectTypeName(expected), d)
+			return
+		}
+		fail(self, "actual value %q (%s) should be equal to expected value %q (%s)",
+			actual, objectTypeName(actual),
+			expected, objectTypeName(expected))
+		return
+	}
+	if ab, ok := bytesOf(a); ok {
+		if eb, ok := bytesOf(e); ok {
+			if pos := firstByteMismatch(ab, eb); pos >= 0 {
+				fail(self, "actual value (%s, %d bytes) should be equal to expected value (%s, %d bytes) - First difference at byte offset %d:\n%s",
+					objectTypeName(actual), len(ab),
+					objectTypeName(expected), len(eb),
+					pos, hexDiff(ab, eb, 0, pos))
+				return
+			}
+		}
+	}
+	if note := typedNilNote(actual, expected); note != "" {
 		fail(self, "actual value '%v' (%s) should be equal to expected value '%v' (%s)%s",
 			actual, objectTypeName(actual),
 			expected, objectTypeName(expected),
-			diff(actual, expected),
-		)
+			note)
+		return
 	}
+	fail(self, "actual value '%v' (%s) should be equal to expected value '%v' (%s)%s",
+		actual, objectTypeName(actual),
+		expected, objectTypeName(expected),
+		diff(actual, expected, self.comparers),
+	)
 }
 
 func (self *asserter) NotEqual(actual interface{}, expected interface{}) {
 	self.tb.Helper()
-	if isEqual(actual, expected) {
+	if len(self.cmpOpts) > 0 && !self.isEqual(actual, expected) {
+		self.NotEqualOpts(actual, expected)
+		return
+	}
+	if self.isEqual(actual, expected) {
+		fail(self, "actual value '%v' (%s) should not be equal to expected value '%v' (%s)",
+			actual, objectTypeName(actual),
+			expected, objectTypeName(expected))
+	}
+}
+
+func (self *asserter) EqualStrict(actual interface{}, expected interface{}) {
+	self.tb.Helper()
+	if reflect.TypeOf(actual) != reflect.TypeOf(expected) {
+		fail(self, "actual value '%v' (%s) should be equal to expected value '%v' (%s), but their types differ%s%s",
+			actual, objectTypeName(actual),
+			expected, objectTypeName(expected),
+			typeMismatch(actual, expected),
+			typedNilNote(actual, expected),
+		)
+		return
+	}
+	if !isEqual(actual, expected, self.comparers) {
+		self.failNotEqual(actual, expected)
+	}
+}
+
+func (self *asserter) EqualNumeric(actual interface{}, expected interface{}) {
+	self.tb.Helper()
+	if diffs := approxDiff(actual, expected, exactlyEqual, self.comparers); len(diffs) > 0 {
+		fail(self, "actual value '%v' (%s) should be numerically equal to expected value '%v' (%s) - Differences:\n%s",
+			actual, objectTypeName(actual),
+			expected, objectTypeName(expected),
+			formatDiffs(diffs),
+		)
+	}
+}
+
+// isEqual compares a and b using EqualStrict semantics if the Strict option
+// is set, and Equal semantics otherwise.
+func (self *asserter) isEqual(a interface{}, b interface{}) bool {
+	if self.strictTypes && reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	return isEqual(a, b, self.comparers)
+}
+
+func (self *asserter) EqualOpts(actual interface{}, expected interface{}, opts ...cmp.Option) {
+	self.tb.Helper()
+	opts = self.withCmpOpts(opts)
+	equal, err := cmpEqual(actual, expected, opts)
+	if err != nil {
+		fail(self, "unable to compare actual value '%v' (%s) to expected value '%v' (%s): %v",
+			actual, objectTypeName(actual),
+			expected, objectTypeName(expected), err)
+		return
+	}
+	if !equal {
+		fail(self, "actual value '%v' (%s) should be equal to expected value '%v' (%s)%s",
+			actual, objectTypeName(actual),
+			expected, objectTypeName(expected),
+			cmpDiff(actual, expected, opts),
+		)
+	}
+}
+
+func (self *asserter) NotEqualOpts(actual interface{}, expected interface{}, opts ...cmp.Option) {
+	self.tb.Helper()
+	equal, err := cmpEqual(actual, expected, self.withCmpOpts(opts))
+	if err != nil {
+		fail(self, "unable to compare actual value '%v' (%s) to expected value '%v' (%s): %v",
+			actual, objectTypeName(actual),
+			expected, objectTypeName(expected), err)
+		return
+	}
+	if equal {
 		fail(self, "actual value '%v' (%s) should not be equal to expected value '%v' (%s)",
 			actual, objectTypeName(actual),
 			expected, objectTypeName(expected))
 	}
 }
 
+// withCmpOpts returns the default go-cmp options of the asserter followed by
+// the provided options.
+func (self *asserter) withCmpOpts(opts []cmp.Option) []cmp.Option {
+	if len(self.cmpOpts) == 0 {
+		return opts
+	}
+	all := make([]cmp.Option, 0, len(self.cmpOpts)+len(opts))
+	all = append(all, self.cmpOpts...)
+	return append(all, opts...)
+}
+
+func (self *asserter) InDelta(actual interface{}, expected interface{}, delta float64) {
+	self.tb.Helper()
+	if delta < 0 || math.IsNaN(delta) {
+		fail(self, "delta must be a non-negative number, but got: %v", delta)
+		return
+	}
+	if diffs := approxDiff(actual, expected, withinDelta(delta), self.comparers); len(diffs) > 0 {
+		fail(self, "actual value '%v' (%s) should be within delta %v of expected value '%v' (%s) - Differences:\n%s",
+			actual, objectTypeName(actual), delta,
+			expected, objectTypeName(expected),
+			formatDiffs(diffs),
+		)
+	}
+}
+
+func (self *asserter) InEpsilon(actual interface{}, expected interface{}, epsilon float64) {
+	self.tb.Helper()
+	if epsilon < 0 || math.IsNaN(epsilon) {
+		fail(self, "epsilon must be a non-negative number, but got: %v", epsilon)
+		return
+	}
+	if diffs := approxDiff(actual, expected, withinEpsilon(epsilon), self.comparers); len(diffs) > 0 {
+		fail(self, "actual value '%v' (%s) should be within relative error %v of expected value '%v' (%s) - Differences:\n%s",
+			actual, objectTypeName(actual), epsilon,
+			expected, objectTypeName(expected),
+			formatDiffs(diffs),
+		)
+	}
+}
+
+func (self *asserter) InULP(actual interface{}, expected interface{}, ulps uint64) {
+	self.tb.Helper()
+	if diffs := approxDiff(actual, expected, withinULP(ulps), self.comparers); len(diffs) > 0 {
+		fail(self, "actual value '%v' (%s) should be within %d ULPs of expected value '%v' (%s) - Differences:\n%s",
+			actual, objectTypeName(actual), ulps,
+			expected, objectTypeName(expected),
+			formatDiffs(diffs),
+		)
+	}
+}
+
+func (self *asserter) ElementsMatch(actual interface{}, expected interface{}) {
+	self.tb.Helper()
+	aList, ok := listElements(actual)
+	if !ok {
+		fail(self, "expected actual object '%s' to be an array or slice", objectTypeName(actual))
+		return
+	}
+	eList, ok := listElements(expected)
+	if !ok {
+		fail(self, "expected expected object '%s' to be an array or slice", objectTypeName(expected))
+		return
+	}
+	if d := elementsDiff(aList, eList, self.isEqual); d != "" {
+		fail(self, "actual elements '%v' (%s) should match expected elements '%v' (%s) - Differences:\n%s",
+			actual, objectTypeName(actual),
+			expected, objectTypeName(expected),
+			d,
+		)
+	}
+}
+
+func (self *asserter) Subset(actual interface{}, expected interface{}) {
+	self.tb.Helper()
+	if diffs := subsetDiff(actual, expected, self.comparers); len(diffs) > 0 {
+		fail(self, "actual value '%v' (%s) should match the non-zero values of expected value '%v' (%s) - Diff (actual != expected):\n%s",
+			actual, objectTypeName(actual),
+			expected, objectTypeName(expected),
+			formatDiffs(diffs),
+		)
+	}
+}
+
+func (self *asserter) JSONEq(actual interface{}, expected interface{}) {
+	self.tb.Helper()
+	a, err := decodeJSON(actual)
+	if err != nil {
+		fail(self, "expected actual object '%s' to be valid JSON, but got: %v", objectTypeName(actual), err)
+		return
+	}
+	e, err := decodeJSON(expected)
+	if err != nil {
+		fail(self, "expected expected object '%s' to be valid JSON, but got: %v", objectTypeName(expected), err)
+		return
+	}
+	if diffs := jsonDiff("", a, e, nil); len(diffs) > 0 {
+		fail(self, "actual JSON '%s' should be equal to expected JSON '%s' - Diff (actual != expected):\n%s",
+			formatJSON(a), formatJSON(e),
+			formatDiffs(diffs),
+		)
+	}
+}
+
 func (self *asserter) OneOf(a interface{}, b ...interface{}) {
 	self.tb.Helper()
 	result := false
 	for _, o := range b {
-		result = isEqual(a, o)
+		result = self.isEqual(a, o)
 		if result {
 			break
 		}
@@ -244,7 +918,7 @@ func (self *asserter) NotOneOf(a interface{}, b ...interface{}) {
 	self.tb.Helper()
 	result := false
 	for _, o := range b {
-		result = isEqual(a, o)
+		result = self.isEqual(a, o)
 		if result {
 			break
 		}
@@ -256,6 +930,38 @@ func (self *asserter) NotOneOf(a interface{}, b ...interface{}) {
 	}
 }
 
+func (self *asserter) Contains(container interface{}, elem interface{}) {
+	self.tb.Helper()
+	found, _, err := contains(container, elem, self.isEqual)
+	if err != nil {
+		fail(self, "expected '%v' (%s) to contain '%v' (%s), but %v",
+			container, objectTypeName(container),
+			elem, objectTypeName(elem), err)
+		return
+	}
+	if !found {
+		fail(self, "expected '%v' (%s) to contain '%v' (%s)",
+			container, objectTypeName(container),
+			elem, objectTypeName(elem))
+	}
+}
+
+func (self *asserter) NotContains(container interface{}, elem interface{}) {
+	self.tb.Helper()
+	found, where, err := contains(container, elem, self.isEqual)
+	if err != nil {
+		fail(self, "expected '%v' (%s) not to contain '%v' (%s), but %v",
+			container, objectTypeName(container),
+			elem, objectTypeName(elem), err)
+		return
+	}
+	if found {
+		fail(self, "expected '%v' (%s) not to contain '%v' (%s), but it was found at %s",
+			container, objectTypeName(container),
+			elem, objectTypeName(elem), where)
+	}
+}
+
 func (self *asserter) Err(err error) {
 	self.tb.Helper()
 	if isNil(err) {
@@ -270,6 +976,60 @@ func (self *asserter) NotErr(err error) {
 	}
 }
 
+func (self *asserter) ErrIs(err error, target error) {
+	self.tb.Helper()
+	if !errors.Is(err, target) {
+		fail(self, "expected error chain to contain target '%v' (%s)%s",
+			target, objectTypeName(target), errorChain(err))
+	}
+}
+
+func (self *asserter) ErrAs(err error, target interface{}) interface{} {
+	self.tb.Helper()
+	v := reflect.ValueOf(target)
+	if !v.IsValid() || v.Kind() != reflect.Ptr || v.IsNil() {
+		fail(self, "expected target '%s' to be a non-nil pointer", objectTypeName(target))
+		return nil
+	}
+	if t := v.Type().Elem(); t.Kind() != reflect.Interface && !t.Implements(errorType) {
+		fail(self, "expected target '%s' to point to an interface or to a type implementing error", objectTypeName(target))
+		return nil
+	}
+	if !errors.As(err, target) {
+		fail(self, "expected error chain to contain an error assignable to '%s'%s",
+			v.Type().Elem(), errorChain(err))
+		return nil
+	}
+	return v.Elem().Interface()
+}
+
+func (self *asserter) ErrContains(err error, substr string) {
+	self.tb.Helper()
+	if isNil(err) {
+		fail(self, "expected error containing %q, but got nil", substr)
+		return
+	}
+	if !strings.Contains(err.Error(), substr) {
+		fail(self, "expected error message %q to contain %q%s", err.Error(), substr, errorChain(err))
+	}
+}
+
+func (self *asserter) ErrMatches(err error, pattern string) {
+	self.tb.Helper()
+	re, compileErr := compileRegexp(pattern)
+	if compileErr != nil {
+		fail(self, "invalid regular expression %q: %v", pattern, compileErr)
+		return
+	}
+	if isNil(err) {
+		fail(self, "expected error matching %q, but got nil", pattern)
+		return
+	}
+	if !re.MatchString(err.Error()) {
+		fail(self, "expected error message %q to match %q%s", err.Error(), pattern, errorChain(err))
+	}
+}
+
 func (self *asserter) Nil(o interface{}) {
 	self.tb.Helper()
 	if !isNil(o) {
@@ -277,6 +1037,29 @@ func (self *asserter) Nil(o interface{}) {
 	}
 }
 
+func (self *asserter) NilInterface(o interface{}) {
+	self.tb.Helper()
+	if o == nil {
+		return
+	}
+	if isNil(o) {
+		fail(self, "expected a nil interface, but got an interface holding a nil %s; an interface holding a typed nil is not itself nil", objectTypeName(o))
+		return
+	}
+	fail(self, "expected a nil interface, but got: %v (%s)", o, objectTypeName(o))
+}
+
+func (self *asserter) TypedNil(o interface{}) {
+	self.tb.Helper()
+	if o == nil {
+		fail(self, "expected a typed nil, but got a nil interface")
+		return
+	}
+	if !isNil(o) {
+		fail(self, "expected a typed nil, but got: %v (%s)", o, objectTypeName(o))
+	}
+}
+
 func (self *asserter) NotNil(o interface{}) {
 	self.tb.Helper()
 	if isNil(o) {
@@ -300,32 +1083,84 @@ func (self *asserter) False(b bool) {
 
 func (self *asserter) Zero(o interface{}) {
 	self.tb.Helper()
-	if !isZero(o) {
-		fail(self, "expected object '%s' to be zero value, but it was: %v", objectTypeName(o), o)
+	if zero, rule := isZero(o); !zero {
+		fail(self, "expected object '%s' to be zero value, but it was: %v - Rule: %s", objectTypeName(o), o, rule)
 	}
 }
 
 func (self *asserter) NotZero(o interface{}) {
 	self.tb.Helper()
-	if isZero(o) {
-		fail(self, "expected object '%s' not to be zero value", objectTypeName(o))
+	if zero, rule := isZero(o); zero {
+		fail(self, "expected object '%s' not to be zero value - Rule: %s", objectTypeName(o), rule)
+	}
+}
+
+func (self *asserter) ZeroValue(o interface{}) {
+	self.tb.Helper()
+	if o != nil && !reflect.ValueOf(o).IsZero() {
+		fail(self, "expected object '%s' to be the zero value of its type, but it was: %v - Rule: reflect.Value.IsZero",
+			objectTypeName(o), truncated(o))
+	}
+}
+
+func (self *asserter) NotZeroValue(o interface{}) {
+	self.tb.Helper()
+	if o == nil || reflect.ValueOf(o).IsZero() {
+		fail(self, "expected object '%s' not to be the zero value of its type - Rule: reflect.Value.IsZero", objectTypeName(o))
 	}
 }
 
 func (self *asserter) Len(obj interface{}, length int) {
 	self.tb.Helper()
-	t := reflect.TypeOf(obj)
-	if obj == nil ||
-		(t.Kind() != reflect.Array &&
-			t.Kind() != reflect.Slice &&
-			t.Kind() != reflect.Map) {
-		fail(self, "expected object '%s' to be of length '%d', but the object is not one of array, slice or map", objectTypeName(obj), length)
+	rLen, ok := lengthOf(obj)
+	if !ok {
+		fail(self, "expected object '%s' to be of length '%d', but the object has no length", objectTypeName(obj), length)
 		return
 	}
-
-	rLen := reflect.ValueOf(obj).Len()
 	if rLen != length {
-		fail(self, "expected object '%s' to be of length '%d' but it was: %d", objectTypeName(obj), length, rLen)
+		fail(self, "expected object '%s' to be of length '%d' but it was: %d - Value: %s",
+			objectTypeName(obj), length, rLen, truncated(obj))
+	}
+}
+
+func (self *asserter) Cap(obj interface{}, capacity int) {
+	self.tb.Helper()
+	rCap, ok := capacityOf(obj)
+	if !ok {
+		fail(self, "expected object '%s' to have capacity '%d', but the object has no capacity", objectTypeName(obj), capacity)
+		return
+	}
+	if rCap != capacity {
+		fail(self, "expected object '%s' to have capacity '%d' but it was: %d - Value: %s",
+			objectTypeName(obj), capacity, rCap, truncated(obj))
+	}
+}
+
+func (self *asserter) Empty(obj interface{}) {
+	self.tb.Helper()
+	if obj == nil {
+		return
+	}
+	rLen, ok := lengthOf(obj)
+	if !ok {
+		fail(self, "expected object '%s' to be empty, but the object has no length", objectTypeName(obj))
+		return
+	}
+	if rLen != 0 {
+		fail(self, "expected object '%s' to be empty but it was of length: %d - Value: %s",
+			objectTypeName(obj), rLen, truncated(obj))
+	}
+}
+
+func (self *asserter) NotEmpty(obj interface{}) {
+	self.tb.Helper()
+	rLen, ok := lengthOf(obj)
+	if !ok && obj != nil {
+		fail(self, "expected object '%s' not to be empty, but the object has no length", objectTypeName(obj))
+		return
+	}
+	if rLen == 0 {
+		fail(self, "expected object '%s' not to be empty", objectTypeName(obj))
 	}
 }
 
@@ -340,6 +1175,75 @@ func (self *asserter) ShouldPanic(fn func()) {
 	fn()
 }
 
+func (self *asserter) PanicsWith(expected interface{}, fn func()) {
+	self.tb.Helper()
+	panicked, recovered, stack := capturePanic(fn)
+	if !panicked {
+		fail(self, "expected function to panic with '%v' (%s)", expected, objectTypeName(expected))
+		return
+	}
+	if !self.isEqual(recovered, expected) {
+		fail(self, "expected function to panic with '%v' (%s), but it panicked with '%v' (%s) - Stack:\n%s",
+			expected, objectTypeName(expected),
+			recovered, objectTypeName(recovered), stack)
+	}
+}
+
+func (self *asserter) PanicsWithError(target error, fn func()) {
+	self.tb.Helper()
+	panicked, recovered, stack := capturePanic(fn)
+	if !panicked {
+		fail(self, "expected function to panic with error '%v'", target)
+		return
+	}
+	err, ok := recovered.(error)
+	if !ok {
+		fail(self, "expected function to panic with error '%v', but it panicked with '%v' (%s) - Stack:\n%s",
+			target, recovered, objectTypeName(recovered), stack)
+		return
+	}
+	if !errors.Is(err, target) {
+		fail(self, "expected function to panic with error '%v', but it panicked with error '%v'%s - Stack:\n%s",
+			target, err, errorChain(err), stack)
+	}
+}
+
+func (self *asserter) PanicMatches(pattern string, fn func()) {
+	self.tb.Helper()
+	re, err := compileRegexp(pattern)
+	if err != nil {
+		fail(self, "invalid regular expression %q: %v", pattern, err)
+		return
+	}
+	panicked, recovered, stack := capturePanic(fn)
+	if !panicked {
+		fail(self, "expected function to panic with a value matching %q", pattern)
+		return
+	}
+	if s := fmt.Sprint(recovered); !re.MatchString(s) {
+		fail(self, "expected function to panic with a value matching %q, but it panicked with %q - Stack:\n%s",
+			pattern, s, stack)
+	}
+}
+
+func (self *asserter) NotPanics(fn func()) {
+	self.tb.Helper()
+	if panicked, recovered, stack := capturePanic(fn); panicked {
+		fail(self, "expected function not to panic, but it panicked with '%v' (%s) - Stack:\n%s",
+			recovered, objectTypeName(recovered), stack)
+	}
+}
+
+func (self *asserter) PanicValue(fn func()) (interface{}, []byte) {
+	self.tb.Helper()
+	panicked, recovered, stack := capturePanic(fn)
+	if !panicked {
+		fail(self, "expected function to panic")
+		return nil, nil
+	}
+	return recovered, stack
+}
+
 func (self *asserter) EqualType(expected, actual interface{}) {
 	self.tb.Helper()
 	if reflect.TypeOf(expected) != reflect.TypeOf(actual) {
@@ -349,28 +1253,33 @@ func (self *asserter) EqualType(expected, actual interface{}) {
 
 func (self *asserter) WaitForTrue(timeout time.Duration, f func() bool) {
 	self.tb.Helper()
-	after := time.After(timeout)
-	for {
-		select {
-		case <-after:
-			fail(self, "function did not return true within the timeout of %v", timeout)
-			return
-		default:
-			if f() {
-				return
-			}
-			time.Sleep(100 * time.Millisecond)
-		}
+	p, _ := newPoller(waitForTruePoll, nil)
+	r := p.run(self, context.Background(), time.Now().Add(timeout), f)
+	if !r.ok {
+		fail(self, "function did not return true %s%s", r.describe(timeout), goroutines())
+	}
+}
+
+func (self *asserter) WaitForTrueCtx(ctx context.Context, f func() bool) {
+	self.tb.Helper()
+	p, _ := newPoller(waitForTruePoll, nil)
+	r := p.run(self, ctx, time.Time{}, f)
+	if !r.ok {
+		fail(self, "function did not return true %s%s", r.describe(0), goroutines())
 	}
 }
 
 func (self *asserter) Lax(fn func(lax Asserter)) {
 	lax := &asserter{
-		tb:         self.tb,
-		strict:     false,
-		failFormat: self.failFormat,
-		failArgs:   self.failArgs,
-		failed:     false,
+		tb:          self.tb,
+		strict:      false,
+		failFormat:  self.failFormat,
+		failArgs:    self.failArgs,
+		failed:      false,
+		cmpOpts:     self.cmpOpts,
+		strictTypes: self.strictTypes,
+		comparers:   self.comparers,
+		context:     self.context,
 	}
 
 	fn(lax)
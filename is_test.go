@@ -1,11 +1,18 @@
 package is
 
 import (
+	"bytes"
+	"container/list"
 	"errors"
 	"fmt"
+	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 var numberTypes = []reflect.Type{
@@ -359,3 +366,725 @@ func TestEqualer(t *testing.T) {
 		t.Fatalf("fail func should have been called 2 times, but was called %d times", hit)
 	}
 }
+
+type cmpRecord struct {
+	ID      string
+	Name    string
+	Score   float64
+	Created time.Time
+	secret  int
+}
+
+func TestEqualOpts(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	format := ""
+	fail = func(is *asserter, f string, args ...interface{}) {
+		hit++
+		format = f
+	}
+
+	x := 0.1
+	a := cmpRecord{ID: "a", Name: "test", Score: x + 0.2, Created: time.Now()}
+	b := cmpRecord{ID: "b", Name: "test", Score: 0.3}
+	ignore := cmpopts.IgnoreFields(cmpRecord{}, "ID", "Created")
+	approx := cmpopts.EquateApprox(0, 1e-9)
+	unexported := cmpopts.IgnoreUnexported(cmpRecord{})
+
+	assert.EqualOpts(a, b, ignore, approx, unexported)
+	if hit != 0 {
+		t.Fatalf("expected no failures, but got %d", hit)
+	}
+
+	assert.EqualOpts(a, b, ignore, unexported)
+	if hit != 1 {
+		t.Fatalf("expected 1 failure, but got %d", hit)
+	}
+
+	assert.EqualOpts([]int{3, 1, 2}, []int{1, 2, 3}, cmpopts.SortSlices(func(a, b int) bool { return a < b }))
+	if hit != 1 {
+		t.Fatalf("expected 1 failure, but got %d", hit)
+	}
+
+	assert.EqualOpts("abc", "ABC", cmp.Comparer(strings.EqualFold))
+	if hit != 1 {
+		t.Fatalf("expected 1 failure, but got %d", hit)
+	}
+
+	assert.NotEqualOpts(a, b, ignore, approx, unexported)
+	if hit != 2 {
+		t.Fatalf("expected 2 failures, but got %d", hit)
+	}
+
+	assert.NotEqualOpts(a, b, ignore, unexported)
+	if hit != 2 {
+		t.Fatalf("expected 2 failures, but got %d", hit)
+	}
+
+	// cmp.Equal panics on unexported fields without an option.
+	assert.EqualOpts(a, a)
+	if hit != 3 {
+		t.Fatalf("expected 3 failures, but got %d", hit)
+	}
+	if !strings.HasPrefix(format, "unable to compare") {
+		t.Fatalf("expected comparison error, but got: %s", format)
+	}
+
+	fail = failDefault
+}
+
+func TestCmpOptions(t *testing.T) {
+	hit := 0
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+	}
+
+	x := 0.1
+	a := cmpRecord{ID: "a", Name: "test", Score: x + 0.2}
+	b := cmpRecord{ID: "b", Name: "test", Score: 0.3}
+
+	opts := New(t, CmpOptions(cmpopts.IgnoreFields(cmpRecord{}, "ID"), cmpopts.IgnoreUnexported(cmpRecord{})))
+	opts.Equal(a, b)
+	if hit != 1 {
+		t.Fatalf("expected 1 failure, but got %d", hit)
+	}
+
+	opts.EqualOpts(a, b, cmpopts.EquateApprox(0, 1e-9))
+	if hit != 1 {
+		t.Fatalf("expected 1 failure, but got %d", hit)
+	}
+
+	opts.Msg("message").Equal(a, b)
+	if hit != 2 {
+		t.Fatalf("expected 2 failures, but got %d", hit)
+	}
+
+	opts.NotEqual(a, b)
+	if hit != 2 {
+		t.Fatalf("expected 2 failures, but got %d", hit)
+	}
+
+	b.Name = "other"
+	opts.NotEqual(a, b)
+	if hit != 2 {
+		t.Fatalf("expected 2 failures, but got %d", hit)
+	}
+
+	// The usual equality rules still apply when options are set.
+	opts.Equal(int32(1), int64(1))
+	opts.Equal(&equaler{equal: true}, &equaler{equal: true})
+	opts.WithComparer(func(a, b cmpRecord) bool { return a.Name == b.Name }).Equal(a, cmpRecord{Name: "test"})
+	if hit != 2 {
+		t.Fatalf("expected 2 failures, but got %d", hit)
+	}
+
+	opts.NotEqual(int32(1), int64(1))
+	if hit != 3 {
+		t.Fatalf("expected 3 failures, but got %d", hit)
+	}
+
+	fail = failDefault
+}
+
+type elementsEvent struct {
+	ID   int
+	Seen time.Time
+}
+
+func (e elementsEvent) IsEqual(in interface{}) bool {
+	o, ok := in.(elementsEvent)
+	return ok && e.ID == o.ID
+}
+
+// nearby values are equal if they differ by at most 1, which is not
+// transitive.
+type nearby int
+
+func (n nearby) IsEqual(in interface{}) bool {
+	o, ok := in.(nearby)
+	return ok && n-o <= 1 && o-n <= 1
+}
+
+func TestElementsMatch(t *testing.T) {
+	assert := New(t)
+
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	passes := []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{[]int{1, 2, 3}, []int{3, 1, 2}},
+		{[]int{1, 1, 2}, [3]int{1, 2, 1}},
+		{[]int{}, []int(nil)},
+		{[]int64{1, 2}, []int32{2, 1}},
+		{[]interface{}{"a", 1, []int{2}}, []interface{}{[]int{2}, 1, "a"}},
+		{[]elementsEvent{{1, time.Now()}, {2, time.Now()}}, []elementsEvent{{2, time.Time{}}, {1, time.Time{}}}},
+		// A first-fit match would pair 1 with 2, leaving 3 unmatched.
+		{[]nearby{2, 0}, []nearby{1, 3}},
+	}
+	for i, test := range passes {
+		msg = ""
+		assert.ElementsMatch(test.actual, test.expected)
+		if msg != "" {
+			t.Fatalf("(test #%d) expected no failure, but got: %s", i, msg)
+		}
+	}
+
+	failures := []struct {
+		actual   interface{}
+		expected interface{}
+		lines    string
+	}{
+		{[]int{1, 2}, []int{1, 2, 3}, "\tmissing: 3\n"},
+		{[]int{1, 2, 4}, []int{1, 2}, "\textra: 4\n"},
+		{[]int{1, 1, 2}, []int{1, 2, 2, 2}, "\tmissing: 2 (expected 3, found 1)\n\textra: 1 (expected 1, found 2)\n"},
+		{[]string{"a"}, []string{"b", "b"}, "\tmissing: \"b\"\n\textra: \"a\"\n"},
+	}
+	for i, test := range failures {
+		msg = ""
+		assert.ElementsMatch(test.actual, test.expected)
+		if !strings.HasSuffix(msg, test.lines) {
+			t.Fatalf("(test #%d) expected failure ending with %q, but got: %s", i, test.lines, msg)
+		}
+	}
+
+	msg = ""
+	assert.ElementsMatch(map[int]int{}, []int{})
+	if !strings.Contains(msg, "to be an array or slice") {
+		t.Fatalf("expected failure for map, but got: %s", msg)
+	}
+
+	msg = ""
+	New(t, Strict()).ElementsMatch([]int64{1, 2}, []int32{2, 1})
+	if msg == "" {
+		t.Fatalf("expected failure for different element types in strict mode")
+	}
+
+	fail = failDefault
+}
+
+type subsetAddress struct {
+	City string
+	Zip  string
+}
+
+type subsetUser struct {
+	ID      int
+	Name    string
+	Created time.Time
+	Address *subsetAddress
+	Tags    []string
+	Meta    map[string]interface{}
+}
+
+func TestSubset(t *testing.T) {
+	assert := New(t)
+
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	actual := subsetUser{
+		ID:      42,
+		Name:    "bob",
+		Created: time.Now(),
+		Address: &subsetAddress{City: "Paris", Zip: "75001"},
+		Tags:    []string{"a", "b"},
+		Meta:    map[string]interface{}{"role": "admin", "nested": map[string]interface{}{"x": 1, "y": 2}},
+	}
+
+	passes := []interface{}{
+		subsetUser{},
+		subsetUser{Name: "bob"},
+		subsetUser{Address: &subsetAddress{City: "Paris"}},
+		subsetUser{Tags: []string{"a", "b"}},
+		subsetUser{Meta: map[string]interface{}{"role": "admin"}},
+		subsetUser{Meta: map[string]interface{}{"nested": map[string]interface{}{"y": 2}}},
+	}
+	for i, expected := range passes {
+		msg = ""
+		assert.Subset(actual, expected)
+		if msg != "" {
+			t.Fatalf("(test #%d) expected no failure, but got: %s", i, msg)
+		}
+	}
+
+	failures := []struct {
+		expected interface{}
+		lines    []string
+	}{
+		{subsetUser{Name: "alice", ID: 1}, []string{".ID: 42 != 1", `.Name: "bob" != "alice"`}},
+		{subsetUser{Address: &subsetAddress{Zip: "75002"}}, []string{`.Address.Zip: "75001" != "75002"`}},
+		{subsetUser{Tags: []string{"a"}}, []string{`.Tags[1]: "b" != <missing>`}},
+		{subsetUser{Meta: map[string]interface{}{"missing": true}}, []string{`.Meta["missing"]: <missing> != true`}},
+		{subsetUser{Meta: map[string]interface{}{"nested": map[string]interface{}{"x": 2}}}, []string{`.Meta["nested"]["x"]: 1 != 2`}},
+		{subsetAddress{}, []string{"(root): is.subsetUser{"}},
+	}
+	for i, test := range failures {
+		msg = ""
+		assert.Subset(actual, test.expected)
+		for _, l := range test.lines {
+			if !strings.Contains(msg, "\t"+l) {
+				t.Fatalf("(test #%d) expected failure containing %q, but got: %s", i, l, msg)
+			}
+		}
+	}
+
+	fail = failDefault
+}
+
+type errCode struct {
+	code int
+}
+
+func (e *errCode) Error() string {
+	return fmt.Sprintf("code %d", e.code)
+}
+
+func TestErrChain(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	sentinel := errors.New("sentinel")
+	coded := &errCode{code: 404}
+	err := fmt.Errorf("request failed: %w", errors.Join(sentinel, fmt.Errorf("status: %w", coded)))
+
+	assert.ErrIs(err, sentinel)
+	var target *errCode
+	if v := assert.ErrAs(err, &target); v != coded || target != coded {
+		t.Fatalf("expected ErrAs to extract the wrapped error, but got %v", v)
+	}
+	if v := ErrAs[*errCode](assert, err); v != coded {
+		t.Fatalf("expected generic ErrAs to extract the wrapped error, but got %v", v)
+	}
+	assert.ErrContains(err, "status: code 404")
+	assert.ErrMatches(err, `code \d+$`)
+	if hit != 0 {
+		t.Fatalf("expected no failures, but got: %s", msg)
+	}
+
+	assert.ErrIs(err, errors.New("other"))
+	expectedChain := " - Error chain:\n" +
+		"\t*fmt.wrapError: \"request failed: sentinel\\nstatus: code 404\"\n" +
+		"\t  *errors.joinError: \"sentinel\\nstatus: code 404\"\n" +
+		"\t    *errors.errorString: \"sentinel\"\n" +
+		"\t    *fmt.wrapError: \"status: code 404\"\n" +
+		"\t      *is.errCode: \"code 404\""
+	if hit != 1 || !strings.HasSuffix(msg, expectedChain) {
+		t.Fatalf("expected failure with error chain:\n%s\nbut got:\n%s", expectedChain, msg)
+	}
+
+	var pathErr *os.PathError
+	if v := assert.ErrAs(err, &pathErr); v != nil || hit != 2 || !strings.Contains(msg, "assignable to '*fs.PathError'") {
+		t.Fatalf("expected ErrAs failure, but got: %s", msg)
+	}
+
+	assert.ErrAs(err, (**errCode)(nil))
+	if hit != 3 || !strings.Contains(msg, "to be a non-nil pointer") {
+		t.Fatalf("expected invalid target failure, but got: %s", msg)
+	}
+
+	var notErr *int
+	assert.ErrAs(err, &notErr)
+	if hit != 4 || !strings.Contains(msg, "to point to an interface or to a type implementing error") {
+		t.Fatalf("expected invalid target failure, but got: %s", msg)
+	}
+
+	assert.ErrContains(err, "missing")
+	assert.ErrContains(nil, "missing")
+	assert.ErrMatches(err, `^status`)
+	assert.ErrMatches(nil, `.`)
+	assert.ErrIs(nil, sentinel)
+	if hit != 9 {
+		t.Fatalf("expected 9 failures, but got %d", hit)
+	}
+
+	assert.ErrMatches(err, `(`)
+	if hit != 10 || !strings.HasPrefix(msg, "invalid regular expression") {
+		t.Fatalf("expected invalid pattern failure, but got: %s", msg)
+	}
+
+	assert.ErrIs(fmt.Errorf("wrapped: %w", (*errCode)(nil)), sentinel)
+	expectedChain = " - Error chain:\n" +
+		"\t*fmt.wrapError: \"wrapped: <nil>\"\n" +
+		"\t  *is.errCode: <nil>"
+	if hit != 11 || !strings.HasSuffix(msg, expectedChain) {
+		t.Fatalf("expected failure with typed nil in error chain:\n%s\nbut got:\n%s", expectedChain, msg)
+	}
+
+	fail = failDefault
+}
+
+func TestPanics(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	sentinel := errors.New("sentinel")
+	assert.PanicsWith("boom", func() { panic("boom") })
+	assert.PanicsWith(42, func() { panic(42) })
+	assert.PanicsWithError(sentinel, func() { panic(fmt.Errorf("wrapped: %w", sentinel)) })
+	assert.PanicMatches(`^index out of range`, func() { panic("index out of range [3]") })
+	assert.NotPanics(func() {})
+	v, stack := assert.PanicValue(func() { panic("boom") })
+	if hit != 0 {
+		t.Fatalf("expected no failures, but got: %s", msg)
+	}
+	if v != "boom" {
+		t.Fatalf("expected recovered value 'boom', but got %v", v)
+	}
+	if !strings.Contains(string(stack), "TestPanics") {
+		t.Fatalf("expected stack of the panicking goroutine, but got:\n%s", stack)
+	}
+
+	assert.PanicsWith("boom", func() {})
+	if hit != 1 || msg != "expected function to panic with 'boom' (string)" {
+		t.Fatalf("expected failure for a function that does not panic, but got: %s", msg)
+	}
+
+	assert.PanicsWith("boom", func() { panic("bang") })
+	if hit != 2 || !strings.HasPrefix(msg, "expected function to panic with 'boom' (string), but it panicked with 'bang' (string) - Stack:\n") {
+		t.Fatalf("expected mismatched panic failure, but got: %s", msg)
+	}
+
+	assert.PanicsWithError(sentinel, func() { panic("sentinel") })
+	if hit != 3 || !strings.Contains(msg, "but it panicked with 'sentinel' (string)") {
+		t.Fatalf("expected non-error panic failure, but got: %s", msg)
+	}
+
+	assert.PanicsWithError(sentinel, func() { panic(errors.New("other")) })
+	if hit != 4 || !strings.Contains(msg, "but it panicked with error 'other'") {
+		t.Fatalf("expected mismatched error failure, but got: %s", msg)
+	}
+
+	assert.PanicMatches(`^bang`, func() { panic("boom") })
+	assert.PanicMatches(`.`, func() {})
+	if hit != 6 {
+		t.Fatalf("expected 6 failures, but got %d", hit)
+	}
+
+	assert.PanicMatches(`(`, func() { t.Fatal("function should not be called") })
+	if hit != 7 || !strings.HasPrefix(msg, "invalid regular expression") {
+		t.Fatalf("expected invalid pattern failure, but got: %s", msg)
+	}
+
+	assert.NotPanics(func() { panic("boom") })
+	if hit != 8 || !strings.HasPrefix(msg, "expected function not to panic, but it panicked with 'boom' (string) - Stack:\n") ||
+		!strings.Contains(msg, "TestPanics") {
+		t.Fatalf("expected panic failure with stack, but got: %s", msg)
+	}
+
+	if v, stack := assert.PanicValue(func() {}); v != nil || stack != nil || hit != 9 || msg != "expected function to panic" {
+		t.Fatalf("expected failure for a function that does not panic, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
+
+func TestContainsAsserter(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	assert.Contains("hello world", "o w")
+	assert.Contains("hello world", 'w')
+	assert.Contains([]byte("hello world"), "world")
+	assert.Contains([]byte("hello"), byte('e'))
+	assert.Contains([]int{1, 2, 3}, 2)
+	assert.Contains([]int64{1, 2, 3}, 3)
+	assert.Contains([2]testStruct{{v: 1}, {v: 2}}, testStruct{v: 2})
+	assert.Contains(map[string]int{"a": 1}, "a")
+	assert.NotContains("hello", "z")
+	assert.NotContains([]int{1, 2, 3}, 4)
+	assert.NotContains(map[string]int{"a": 1}, "b")
+	if hit != 0 {
+		t.Fatalf("expected no failures, but got: %s", msg)
+	}
+
+	assert.Contains([]int{1, 2, 3}, 4)
+	if hit != 1 || msg != "expected '[1 2 3]' ([]int) to contain '4' (int)" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Contains("hello", "z")
+	if hit != 2 || msg != "expected 'hello' (string) to contain 'z' (string)" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.NotContains("hello", "ll")
+	if hit != 3 || msg != "expected 'hello' (string) not to contain 'll' (string), but it was found at byte offset 2" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.NotContains([]string{"a", "b"}, "b")
+	if hit != 4 || !strings.HasSuffix(msg, "but it was found at index 1") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.NotContains(map[string]int{"a": 1}, "a")
+	if hit != 5 || !strings.HasSuffix(msg, "but it was found at key 'a'") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Contains("hello", 42)
+	if hit != 6 || !strings.HasSuffix(msg, "but cannot search string for 'int'") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Contains(make(chan int), 42)
+	if hit != 7 || !strings.HasSuffix(msg, "but cannot search 'chan int' without receiving from it") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.NotContains(42, 42)
+	if hit != 8 || !strings.HasSuffix(msg, "but 'int' is not one of string, array, slice or map") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
+
+type lengthy int
+
+func (l lengthy) Len() int { return int(l) }
+
+func TestLen(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	ch := make(chan int, 5)
+	ch <- 1
+	l := list.New()
+	l.PushBack(1)
+	l.PushBack(2)
+	assert.Len("héllo", 6)
+	assert.Len(ch, 1)
+	assert.Len(&[4]int{}, 4)
+	assert.Len(bytes.NewBufferString("abc"), 3)
+	assert.Len(l, 2)
+	assert.Len(lengthy(7), 7)
+	assert.Len((*bytes.Buffer)(nil), 0)
+	assert.Cap(ch, 5)
+	assert.Cap(make([]int, 1, 10), 10)
+	assert.Cap([3]int{}, 3)
+	assert.Empty(nil)
+	assert.Empty("")
+	assert.Empty([]int(nil))
+	assert.Empty(map[string]int{})
+	assert.Empty(list.New())
+	assert.NotEmpty("a")
+	assert.NotEmpty(ch)
+	assert.NotEmpty(l)
+	if hit != 0 {
+		t.Fatalf("expected no failures, but got: %s", msg)
+	}
+
+	assert.Len("abc", 2)
+	if hit != 1 || msg != "expected object 'string' to be of length '2' but it was: 3 - Value: abc" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Len(42, 2)
+	if hit != 2 || msg != "expected object 'int' to be of length '2', but the object has no length" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Cap("abc", 3)
+	if hit != 3 || msg != "expected object 'string' to have capacity '3', but the object has no capacity" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Cap(make([]int, 0, 2), 3)
+	if hit != 4 || msg != "expected object '[]int' to have capacity '3' but it was: 2 - Value: []" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Empty(strings.Repeat("x", 300))
+	expected := "expected object 'string' to be empty but it was of length: 300 - Value: " +
+		strings.Repeat("x", maxPrintLen) + "... (100 more bytes)"
+	if hit != 5 || msg != expected {
+		t.Fatalf("expected truncated failure, but got: %s", msg)
+	}
+
+	assert.NotEmpty([]int{})
+	if hit != 6 || msg != "expected object '[]int' not to be empty" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.NotEmpty(nil)
+	assert.Empty(42)
+	assert.NotEmpty(42)
+	if hit != 9 || msg != "expected object 'int' not to be empty, but the object has no length" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
+
+func TestTypedNil(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	var err error
+	var typed *errCode
+	assert.NilInterface(nil)
+	assert.NilInterface(err)
+	assert.TypedNil(typed)
+	assert.TypedNil([]int(nil))
+	assert.Nil(typed)
+	assert.Nil(err)
+	if hit != 0 {
+		t.Fatalf("expected no failures, but got: %s", msg)
+	}
+
+	err = typed
+	assert.NilInterface(err)
+	if hit != 1 || msg != "expected a nil interface, but got an interface holding a nil *is.errCode; an interface holding a typed nil is not itself nil" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.NilInterface(42)
+	if hit != 2 || msg != "expected a nil interface, but got: 42 (int)" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.TypedNil(nil)
+	if hit != 3 || msg != "expected a typed nil, but got a nil interface" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.TypedNil(&errCode{})
+	if hit != 4 || !strings.HasPrefix(msg, "expected a typed nil, but got: ") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Equal(err, nil)
+	expected := "actual value '<nil>' (*is.errCode) should be equal to expected value '<nil>' (<nil>)" +
+		" - A nil *is.errCode held in an interface is not equal to an untyped nil; use Nil to accept either, or NilInterface to require an untyped nil"
+	if hit != 5 || msg != expected {
+		t.Fatalf("expected failure:\n%s\nbut got:\n%s", expected, msg)
+	}
+
+	New(t, Strict()).Equal(nil, typed)
+	if hit != 6 || !strings.HasSuffix(msg, "Types: <nil> != *is.errCode - A nil *is.errCode held in an interface is not equal to an untyped nil; use Nil to accept either, or NilInterface to require an untyped nil") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Equal(nil, 42)
+	if hit != 7 || strings.Contains(msg, "untyped nil") {
+		t.Fatalf("expected failure without typed nil note, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
+
+type zeroer struct {
+	v    int
+	zero bool
+}
+
+func (z *zeroer) IsZero() bool { return z.zero }
+
+func TestZeroRules(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	utc := time.Time{}
+	local := time.Time{}.In(time.FixedZone("X", 3600))
+	assert.Zero(utc)
+	assert.Zero(local)
+	assert.Zero(zeroer{v: 1, zero: true})
+	assert.Zero(&zeroer{v: 1, zero: true})
+	assert.Zero([3]int{})
+	assert.Zero(&[]int{})
+	assert.NotZero(zeroer{})
+	assert.NotZero([3]int{0, 1, 0})
+	assert.ZeroValue(nil)
+	assert.ZeroValue(0)
+	assert.ZeroValue([]int(nil))
+	assert.ZeroValue(utc)
+	assert.NotZeroValue(local)
+	assert.NotZeroValue([]int{})
+	assert.NotZeroValue(&testStruct{})
+	if hit != 0 {
+		t.Fatalf("expected no failures, but got: %s", msg)
+	}
+
+	assert.Zero(time.Unix(1, 0))
+	if hit != 1 || !strings.HasSuffix(msg, " - Rule: IsZero method") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Zero([]int{1, 2})
+	if hit != 2 || msg != "expected object '[]int' to be zero value, but it was: [1 2] - Rule: length (2)" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Zero(&testStruct{v: 1})
+	if hit != 3 || msg != "expected object '*is.testStruct' to be zero value, but it was: &{1} - Rule: dereferenced pointer, then zero value of the type" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.NotZero([2]string{})
+	if hit != 4 || msg != "expected object '[2]string' not to be zero value - Rule: element-wise zero value" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.NotZero((*testStruct)(nil))
+	if hit != 5 || msg != "expected object '*is.testStruct' not to be zero value - Rule: nil" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.ZeroValue([]int{})
+	if hit != 6 || msg != "expected object '[]int' to be the zero value of its type, but it was: [] - Rule: reflect.Value.IsZero" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.NotZeroValue(nil)
+	assert.NotZeroValue("")
+	if hit != 8 || msg != "expected object 'string' not to be the zero value of its type - Rule: reflect.Value.IsZero" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
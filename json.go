@@ -0,0 +1,143 @@
+package is
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// decodeJSON decodes o into a generic JSON value, with numbers decoded as
+// json.Number to avoid any loss of precision. If o is a []byte, string or
+// io.Reader, it is decoded as JSON text; any other value is first marshaled.
+func decodeJSON(o interface{}) (interface{}, error) {
+	var r io.Reader
+	switch v := o.(type) {
+	case []byte:
+		r = bytes.NewReader(v)
+	case json.RawMessage:
+		r = bytes.NewReader(v)
+	case string:
+		r = strings.NewReader(v)
+	case io.Reader:
+		r = v
+	default:
+		b, err := json.Marshal(o)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var out interface{}
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after top-level JSON value")
+	}
+	return out, nil
+}
+
+// jsonDiff walks two decoded JSON values and records every difference found
+// beneath path, which is an RFC 6901 JSON pointer.
+func jsonDiff(path string, a, e interface{}, diffs []difference) []difference {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		ev, ok := e.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(av)+len(ev))
+		for k := range av {
+			keys = append(keys, k)
+		}
+		for k := range ev {
+			if _, ok := av[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := path + "/" + jsonPointerEscaper.Replace(k)
+			aElem, aOK := av[k]
+			eElem, eOK := ev[k]
+			switch {
+			case !aOK:
+				diffs = append(diffs, difference{path: p, actual: "<missing>", expected: formatJSON(eElem)})
+			case !eOK:
+				diffs = append(diffs, difference{path: p, actual: formatJSON(aElem), expected: "<missing>"})
+			default:
+				diffs = jsonDiff(p, aElem, eElem, diffs)
+			}
+		}
+		return diffs
+	case []interface{}:
+		ev, ok := e.([]interface{})
+		if !ok {
+			break
+		}
+		n := len(av)
+		if len(ev) > n {
+			n = len(ev)
+		}
+		for i := 0; i < n; i++ {
+			p := fmt.Sprintf("%s/%d", path, i)
+			switch {
+			case i >= len(av):
+				diffs = append(diffs, difference{path: p, actual: "<missing>", expected: formatJSON(ev[i])})
+			case i >= len(ev):
+				diffs = append(diffs, difference{path: p, actual: formatJSON(av[i]), expected: "<missing>"})
+			default:
+				diffs = jsonDiff(p, av[i], ev[i], diffs)
+			}
+		}
+		return diffs
+	case json.Number:
+		if ev, ok := e.(json.Number); ok && jsonNumbersEqual(av, ev) {
+			return diffs
+		}
+	default:
+		if a == e {
+			return diffs
+		}
+	}
+	return append(diffs, difference{path: path, actual: formatJSON(a), expected: formatJSON(e)})
+}
+
+// jsonPointerEscaper escapes an object key for use in an RFC 6901 JSON
+// pointer.
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// jsonNumbersEqual compares two JSON numbers by their exact value, so that,
+// for example, 1, 1.0 and 1e0 are equal, while integers too large for a
+// float64 are still compared exactly.
+func jsonNumbersEqual(a, b json.Number) bool {
+	if a == b {
+		return true
+	}
+	ar, ok := new(big.Rat).SetString(string(a))
+	if !ok {
+		return false
+	}
+	br, ok := new(big.Rat).SetString(string(b))
+	if !ok {
+		return false
+	}
+	return ar.Cmp(br) == 0
+}
+
+// formatJSON returns the compact JSON encoding of a decoded JSON value.
+func formatJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
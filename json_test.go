@@ -0,0 +1,80 @@
+package is
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestJSONEq(t *testing.T) {
+	assert := New(t)
+
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	passes := []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{`{"a": 1, "b": [true, null]}`, `{"b":[true,null],"a":1}`},
+		{[]byte(`{"n": 1.0}`), `{"n": 1e0}`},
+		{`{"n": 12345678901234567890}`, strings.NewReader(`{"n": 12345678901234567890.0}`)},
+		{json.RawMessage(`"x"`), `  "x"  `},
+		{map[string]interface{}{"a": []int{1, 2}}, `{"a": [1, 2]}`},
+		{struct {
+			Name string `json:"name"`
+		}{"bob"}, `{"name": "bob"}`},
+	}
+	for i, test := range passes {
+		msg = ""
+		assert.JSONEq(test.actual, test.expected)
+		if msg != "" {
+			t.Fatalf("(test #%d) expected no failure, but got: %s", i, msg)
+		}
+	}
+
+	failures := []struct {
+		actual   interface{}
+		expected interface{}
+		lines    []string
+	}{
+		{`{"a": {"b/c": {"d~e": 1}}}`, `{"a": {"b/c": {"d~e": 2}}}`, []string{"/a/b~1c/d~0e: 1 != 2"}},
+		{`{"n": 12345678901234567890}`, `{"n": 12345678901234567891}`, []string{"/n: 12345678901234567890 != 12345678901234567891"}},
+		{`[1, 2]`, `[1, 2, 3]`, []string{"/2: <missing> != 3"}},
+		{`{"a": 1, "b": 2}`, `{"a": 1, "c": 2}`, []string{"/b: 2 != <missing>", "/c: <missing> != 2"}},
+		{`{"a": "1"}`, `{"a": 1}`, []string{`/a: "1" != 1`}},
+		{`true`, `false`, []string{"(root): true != false"}},
+	}
+	for i, test := range failures {
+		msg = ""
+		assert.JSONEq(test.actual, test.expected)
+		for _, l := range test.lines {
+			if !strings.Contains(msg, "\t"+l+"\n") {
+				t.Fatalf("(test #%d) expected failure containing %q, but got: %s", i, l, msg)
+			}
+		}
+	}
+
+	msg = ""
+	assert.JSONEq(`{"a": 1`, `{}`)
+	if !strings.HasPrefix(msg, "expected actual object 'string' to be valid JSON") {
+		t.Fatalf("expected invalid JSON failure, but got: %s", msg)
+	}
+
+	msg = ""
+	assert.JSONEq(`{}`, `{} {}`)
+	if !strings.Contains(msg, "unexpected data after top-level JSON value") {
+		t.Fatalf("expected trailing data failure, but got: %s", msg)
+	}
+
+	msg = ""
+	assert.JSONEq(make(chan int), `{}`)
+	if !strings.HasPrefix(msg, "expected actual object 'chan int' to be valid JSON") {
+		t.Fatalf("expected marshal failure, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
@@ -0,0 +1,195 @@
+package is
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+	"reflect"
+)
+
+// isNumber reports whether v holds a value of any integer, floating point or
+// complex kind.
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
+
+// toComplex converts a numeric value of any kind to a complex128.
+func toComplex(v reflect.Value) complex128 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return complex(float64(v.Int()), 0)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return complex(float64(v.Uint()), 0)
+	case reflect.Float32, reflect.Float64:
+		return complex(v.Float(), 0)
+	case reflect.Complex64, reflect.Complex128:
+		return v.Complex()
+	}
+	panic(fmt.Sprintf("is: %s is not a numeric type", v.Type()))
+}
+
+// isSinglePrecision reports whether v is a float32 or complex64.
+func isSinglePrecision(v reflect.Value) bool {
+	return v.Kind() == reflect.Float32 || v.Kind() == reflect.Complex64
+}
+
+// withinDelta returns a comparison function that considers two numbers equal
+// if the absolute difference between them is at most delta.
+func withinDelta(delta float64) func(a, e reflect.Value) (string, bool) {
+	return func(a, e reflect.Value) (string, bool) {
+		av, ev := toComplex(a), toComplex(e)
+		if cmplx.IsNaN(av) || cmplx.IsNaN(ev) {
+			return "NaN is not within any delta", false
+		}
+		if av == ev {
+			return "", true
+		}
+		dist := cmplx.Abs(av - ev)
+		if dist > delta {
+			return fmt.Sprintf("delta %v exceeds %v", dist, delta), false
+		}
+		return "", true
+	}
+}
+
+// withinEpsilon returns a comparison function that considers two numbers
+// equal if the relative error between them, |a-e|/|e|, is at most epsilon.
+func withinEpsilon(epsilon float64) func(a, e reflect.Value) (string, bool) {
+	return func(a, e reflect.Value) (string, bool) {
+		av, ev := toComplex(a), toComplex(e)
+		if cmplx.IsNaN(av) || cmplx.IsNaN(ev) {
+			return "NaN is not within any relative error", false
+		}
+		if av == ev {
+			return "", true
+		}
+		if ev == 0 {
+			return "relative error is undefined for an expected value of 0", false
+		}
+		relErr := cmplx.Abs(av-ev) / cmplx.Abs(ev)
+		if relErr > epsilon {
+			return fmt.Sprintf("relative error %v exceeds %v", relErr, epsilon), false
+		}
+		return "", true
+	}
+}
+
+// withinULP returns a comparison function that considers two numbers equal
+// if they are at most ulps units in the last place apart. If both numbers are
+// single precision, the distance is measured in float32 steps; otherwise it
+// is measured in float64 steps. For complex numbers, the larger of the
+// distances between the real and imaginary parts is used.
+func withinULP(ulps uint64) func(a, e reflect.Value) (string, bool) {
+	return func(a, e reflect.Value) (string, bool) {
+		av, ev := toComplex(a), toComplex(e)
+		if cmplx.IsNaN(av) || cmplx.IsNaN(ev) {
+			return "NaN is not within any ULP distance", false
+		}
+		distance := ulpDistance64
+		if isSinglePrecision(a) && isSinglePrecision(e) {
+			distance = ulpDistance32
+		}
+		dist := distance(real(av), real(ev))
+		if d := distance(imag(av), imag(ev)); d > dist {
+			dist = d
+		}
+		if dist > ulps {
+			return fmt.Sprintf("ULP distance %d exceeds %d", dist, ulps), false
+		}
+		return "", true
+	}
+}
+
+// ulpDistance64 returns the number of representable float64 values between
+// a and b.
+func ulpDistance64(a, b float64) uint64 {
+	ia, ib := orderedBits64(a), orderedBits64(b)
+	if ia < ib {
+		ia, ib = ib, ia
+	}
+	return uint64(ia) - uint64(ib)
+}
+
+// ulpDistance32 returns the number of representable float32 values between
+// a and b.
+func ulpDistance32(a, b float64) uint64 {
+	ia, ib := orderedBits32(float32(a)), orderedBits32(float32(b))
+	if ia < ib {
+		ia, ib = ib, ia
+	}
+	return uint64(ia - ib)
+}
+
+// orderedBits64 maps a float64 to an integer such that adjacent floats map to
+// adjacent integers and the ordering of floats is preserved.
+func orderedBits64(f float64) int64 {
+	b := int64(math.Float64bits(f))
+	if b < 0 {
+		b = math.MinInt64 - b
+	}
+	return b
+}
+
+// orderedBits32 maps a float32 to an integer such that adjacent floats map to
+// adjacent integers and the ordering of floats is preserved.
+func orderedBits32(f float32) int64 {
+	b := int64(int32(math.Float32bits(f)))
+	if b < 0 {
+		b = math.MinInt32 - b
+	}
+	return b
+}
+
+// exactlyEqual compares two numbers of any kind by their exact mathematical
+// value, without converting either to the type of the other.
+func exactlyEqual(a, e reflect.Value) (string, bool) {
+	ar, ai := exactParts(a)
+	er, ei := exactParts(e)
+	if ar == nil || ai == nil || er == nil || ei == nil {
+		return "NaN is not equal to any value", false
+	}
+	return "", ar.Cmp(er) == 0 && ai.Cmp(ei) == 0
+}
+
+// exactParts returns the exact real and imaginary parts of a numeric value.
+// A nil part indicates NaN, which big.Float cannot represent.
+func exactParts(v reflect.Value) (re *big.Float, im *big.Float) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Float).SetInt64(v.Int()), new(big.Float)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Float).SetUint64(v.Uint()), new(big.Float)
+	case reflect.Float32, reflect.Float64:
+		return exactFloat(v.Float()), new(big.Float)
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		return exactFloat(real(c)), exactFloat(imag(c))
+	}
+	panic(fmt.Sprintf("is: %s is not a numeric type", v.Type()))
+}
+
+// exactFloat returns f as a big.Float, or nil if f is NaN.
+func exactFloat(f float64) *big.Float {
+	if math.IsNaN(f) {
+		return nil
+	}
+	return new(big.Float).SetFloat64(f)
+}
+
+// approxDiff compares actual and expected, recursing into composite values,
+// and returns every difference found. Numbers are compared with the provided
+// function, which may accept values of different numeric types; all other
+// values must be identical.
+func approxDiff(actual interface{}, expected interface{}, numeric func(a, e reflect.Value) (string, bool), c *comparers) []difference {
+	d := newDiffer(c)
+	d.numeric = numeric
+	return d.run(reflect.ValueOf(actual), reflect.ValueOf(expected))
+}
@@ -0,0 +1,259 @@
+package is
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+type approxPoint struct {
+	Name string
+	X, Y float64
+}
+
+func TestInDelta(t *testing.T) {
+	assert := New(t)
+
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	x := 0.1
+	passes := []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{x + 0.2, 0.3},
+		{3, 3.0000001},
+		{uint8(3), int64(3)},
+		{float32(1.5), 1.5000001},
+		{complex(1, x+0.2), complex(1, 0.3)},
+		{[]float64{x + 0.2, 1}, []float64{0.3, 1}},
+		{[2]float32{1, 2}, [2]float32{1, 2.0000001}},
+		{map[string]float64{"a": x + 0.2}, map[string]float64{"a": 0.3}},
+		{approxPoint{"p", x + 0.2, 1}, approxPoint{"p", 0.3, 1}},
+		{&approxPoint{"p", x + 0.2, 1}, &approxPoint{"p", 0.3, 1}},
+		{[]interface{}{1, x + 0.2}, []interface{}{1.0, 0.3}},
+	}
+	for i, test := range passes {
+		msg = ""
+		assert.InDelta(test.actual, test.expected, 1e-6)
+		if msg != "" {
+			t.Fatalf("(test #%d) expected no failure, but got: %s", i, msg)
+		}
+	}
+
+	failures := []struct {
+		actual   interface{}
+		expected interface{}
+		contains string
+	}{
+		{1.0, 1.1, "(root): 1 != 1.1 (delta"},
+		{complex(1, 1), complex(1, 2), "(root): (1+1i) != (1+2i) (delta 1 exceeds 1e-06)"},
+		{[]float64{1, 2}, []float64{1, 2.5}, "[1]: 2 != 2.5 (delta 0.5 exceeds 1e-06)"},
+		{[]float64{1, 2}, []float64{1}, "[1]: 2 != <missing>"},
+		{map[string]float64{"a": 1}, map[string]float64{"a": 2}, `["a"]: 1 != 2 (delta 1 exceeds 1e-06)`},
+		{approxPoint{"p", 1, 1}, approxPoint{"p", 1, 3}, ".Y: 1 != 3 (delta 2 exceeds 1e-06)"},
+		{approxPoint{"p", 1, 1}, approxPoint{"q", 1, 1}, `.Name: "p" != "q"`},
+		{math.NaN(), math.NaN(), "NaN is not within any delta"},
+	}
+	for i, test := range failures {
+		msg = ""
+		assert.InDelta(test.actual, test.expected, 1e-6)
+		if !strings.Contains(msg, test.contains) {
+			t.Fatalf("(test #%d) expected failure containing %q, but got: %s", i, test.contains, msg)
+		}
+	}
+
+	msg = ""
+	assert.InDelta(1, 1, -1)
+	if !strings.HasPrefix(msg, "delta must be a non-negative number") {
+		t.Fatalf("expected invalid delta failure, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
+
+func TestInEpsilon(t *testing.T) {
+	assert := New(t)
+
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	assert.InEpsilon(100.0, 101, 0.01)
+	assert.InEpsilon([]int{100, 0}, []float64{101, 0}, 0.01)
+	assert.InEpsilon(complex(100, 0), complex(100, 1), 0.01)
+	if msg != "" {
+		t.Fatalf("expected no failure, but got: %s", msg)
+	}
+
+	assert.InEpsilon(100.0, 110.0, 0.01)
+	if !strings.Contains(msg, "(root): 100 != 110 (relative error 0.0909") {
+		t.Fatalf("expected relative error failure, but got: %s", msg)
+	}
+
+	assert.InEpsilon(1, 0, 0.01)
+	if !strings.Contains(msg, "relative error is undefined for an expected value of 0") {
+		t.Fatalf("expected undefined relative error failure, but got: %s", msg)
+	}
+
+	msg = ""
+	assert.InEpsilon(1, 1, math.NaN())
+	if !strings.HasPrefix(msg, "epsilon must be a non-negative number") {
+		t.Fatalf("expected invalid epsilon failure, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
+
+func TestInULP(t *testing.T) {
+	assert := New(t)
+
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	x := 0.1
+	assert.InULP(x+0.2, 0.3, 1)
+	assert.InULP(math.Nextafter(1, 2), 1.0, 1)
+	assert.InULP(0.0, math.Copysign(0, -1), 0)
+	assert.InULP(math.Float64frombits(1), math.Float64frombits(1<<63|1), 2)
+	assert.InULP(math.Nextafter32(1, 2), float32(1), 1)
+	assert.InULP(complex64(complex(1, math.Nextafter32(1, 2))), complex64(complex(1, 1)), 1)
+	assert.InULP([]float64{1, math.Nextafter(2, 3)}, []float64{1, 2}, 1)
+	if msg != "" {
+		t.Fatalf("expected no failure, but got: %s", msg)
+	}
+
+	assert.InULP(x+0.2, 0.3, 0)
+	if !strings.Contains(msg, "(ULP distance 1 exceeds 0)") {
+		t.Fatalf("expected ULP failure, but got: %s", msg)
+	}
+
+	assert.InULP(map[int]float32{1: math.Nextafter32(math.Nextafter32(1, 2), 2)}, map[int]float32{1: 1}, 1)
+	if !strings.Contains(msg, "[1]: 1.0000002 != 1 (ULP distance 2 exceeds 1)") {
+		t.Fatalf("expected ULP failure, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
+
+func TestEqualStrict(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	assert.EqualStrict(int64(42), int64(42))
+	assert.EqualStrict([]int{1}, []int{1})
+	assert.EqualStrict(nil, nil)
+	if hit != 0 {
+		t.Fatalf("expected no failures, but got: %s", msg)
+	}
+
+	assert.EqualStrict(int8(44), int64(300))
+	if hit != 1 || !strings.Contains(msg, "but their types differ - Types: int8 != int64") {
+		t.Fatalf("expected type mismatch failure, but got: %s", msg)
+	}
+
+	assert.EqualStrict("A", 65)
+	if hit != 2 {
+		t.Fatalf("expected string and int to differ")
+	}
+
+	assert.EqualStrict(testStruct{v: 1}, struct{ v int }{v: 1})
+	if hit != 3 || !strings.Contains(msg, "Types: is.testStruct != struct { v int }") {
+		t.Fatalf("expected type mismatch failure, but got: %s", msg)
+	}
+
+	assert.EqualStrict(1, 2)
+	if hit != 4 {
+		t.Fatalf("expected values to differ")
+	}
+
+	strict := New(t, Strict())
+	hit = 0
+	strict.Equal(int32(1), int64(1))
+	strict.NotEqual(int32(1), int32(1))
+	strict.OneOf(int32(1), int64(1), uint(1))
+	strict.Msg("message").Equal(1, 1.0)
+	if hit != 4 {
+		t.Fatalf("expected 4 failures, but got %d", hit)
+	}
+
+	hit = 0
+	strict.Equal(int32(1), int32(1))
+	strict.NotEqual(int32(1), int64(1))
+	strict.NotOneOf(int32(1), int64(1), uint(1))
+	if hit != 0 {
+		t.Fatalf("expected no failures, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
+
+func TestEqualNumeric(t *testing.T) {
+	assert := New(t)
+
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	passes := []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{int8(44), int64(44)},
+		{uint64(1 << 63), float64(1 << 63)},
+		{int64(-1), float32(-1)},
+		{uint8(3), complex(3, 0)},
+		{float32(0.5), 0.5},
+		{[]uint16{1, 2}, []float64{1, 2}},
+		{approxPoint{"p", 1, 2}, approxPoint{"p", 1, 2}},
+	}
+	for i, test := range passes {
+		msg = ""
+		assert.EqualNumeric(test.actual, test.expected)
+		if msg != "" {
+			t.Fatalf("(test #%d) expected no failure, but got: %s", i, msg)
+		}
+	}
+
+	failures := []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{int8(44), int64(300)},
+		{uint64(math.MaxUint64), int64(-1)},
+		{int64(1<<53 + 1), float64(1 << 53)},
+		{float32(0.1), 0.1},
+		{2, complex(2, 1)},
+		{math.NaN(), math.NaN()},
+		{[]int{1, 2}, []int{1, 3}},
+		{"1", 1},
+	}
+	for i, test := range failures {
+		msg = ""
+		assert.EqualNumeric(test.actual, test.expected)
+		if msg == "" {
+			t.Fatalf("(test #%d) expected failure comparing %v and %v", i, test.actual, test.expected)
+		}
+	}
+
+	assert.EqualNumeric(int8(44), int64(300))
+	if !strings.Contains(msg, "(root): int8(44) != int64(300)") {
+		t.Fatalf("expected failure to show types, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
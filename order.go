@@ -0,0 +1,196 @@
+package is
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// compareOrdered compares a and b, returning -1, 0 or +1 if a is less than,
+// equal to or greater than b. Numbers of any real type are compared by their
+// exact values, strings lexically, and values of other types with a method
+// of the form Compare(T) int, such as time.Time.Compare. It returns an error
+// if the values cannot be ordered.
+func compareOrdered(a interface{}, b interface{}) (int, error) {
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !av.IsValid() || !bv.IsValid() {
+		return 0, fmt.Errorf("nil cannot be ordered")
+	}
+
+	switch {
+	case isNumber(av) && isNumber(bv):
+		for _, v := range []reflect.Value{av, bv} {
+			if k := v.Kind(); k == reflect.Complex64 || k == reflect.Complex128 {
+				return 0, fmt.Errorf("'%s' is not an ordered type", v.Type())
+			}
+		}
+		ar, _ := exactParts(av)
+		br, _ := exactParts(bv)
+		if ar == nil || br == nil {
+			return 0, fmt.Errorf("NaN cannot be ordered")
+		}
+		return ar.Cmp(br), nil
+	case av.Kind() == reflect.String && bv.Kind() == reflect.String:
+		return strings.Compare(av.String(), bv.String()), nil
+	}
+
+	if av.Type() != bv.Type() {
+		return 0, fmt.Errorf("'%s' cannot be ordered against '%s'", av.Type(), bv.Type())
+	}
+	if f := compareFunc(av.Type()); f != nil {
+		return f(av, bv), nil
+	}
+	if f := compareFunc(reflect.PtrTo(av.Type())); f != nil {
+		return f(addressable(av).Addr(), addressable(bv).Addr()), nil
+	}
+	return 0, fmt.Errorf("'%s' is not an ordered type", av.Type())
+}
+
+// compareFunc returns a function that compares two values of type t using a
+// method of the form Compare(T) int, or nil if t has none. If t is a pointer
+// type, T may be either t or the type it points to.
+func compareFunc(t reflect.Type) func(x, y reflect.Value) int {
+	m, ok := t.MethodByName("Compare")
+	if !ok || t.Kind() == reflect.Interface {
+		return nil
+	}
+	mt := m.Type
+	if mt.NumIn() != 2 || mt.NumOut() != 1 || mt.Out(0).Kind() != reflect.Int {
+		return nil
+	}
+	deref := false
+	switch {
+	case mt.In(1) == t:
+	case t.Kind() == reflect.Ptr && mt.In(1) == t.Elem():
+		deref = true
+	default:
+		return nil
+	}
+	return func(x, y reflect.Value) int {
+		if deref {
+			y = y.Elem()
+		}
+		return int(m.Func.Call([]reflect.Value{x, y})[0].Int())
+	}
+}
+
+// order compares a and b with compareOrdered. It fails if the values cannot
+// be ordered, describing the comparison that was attempted.
+func (self *asserter) order(a interface{}, b interface{}, relation string) (int, bool) {
+	self.tb.Helper()
+	if self.strictTypes && reflect.TypeOf(a) != reflect.TypeOf(b) {
+		fail(self, "expected '%v' (%s) to be %s '%v' (%s), but their types differ%s",
+			a, objectTypeName(a), relation, b, objectTypeName(b), typeMismatch(a, b))
+		return 0, false
+	}
+	c, err := compareOrdered(a, b)
+	if err != nil {
+		fail(self, "expected '%v' (%s) to be %s '%v' (%s), but %v",
+			a, objectTypeName(a), relation, b, objectTypeName(b), err)
+		return 0, false
+	}
+	return c, true
+}
+
+func (self *asserter) Greater(a interface{}, b interface{}) {
+	self.tb.Helper()
+	self.assertOrder(a, b, "greater than", func(c int) bool { return c > 0 })
+}
+
+func (self *asserter) GreaterOrEqual(a interface{}, b interface{}) {
+	self.tb.Helper()
+	self.assertOrder(a, b, "greater than or equal to", func(c int) bool { return c >= 0 })
+}
+
+func (self *asserter) Less(a interface{}, b interface{}) {
+	self.tb.Helper()
+	self.assertOrder(a, b, "less than", func(c int) bool { return c < 0 })
+}
+
+func (self *asserter) LessOrEqual(a interface{}, b interface{}) {
+	self.tb.Helper()
+	self.assertOrder(a, b, "less than or equal to", func(c int) bool { return c <= 0 })
+}
+
+// assertOrder fails unless ok reports true for the result of comparing a
+// and b.
+func (self *asserter) assertOrder(a interface{}, b interface{}, relation string, ok func(c int) bool) {
+	self.tb.Helper()
+	c, valid := self.order(a, b, relation)
+	if valid && !ok(c) {
+		fail(self, "expected '%v' (%s) to be %s '%v' (%s)",
+			a, objectTypeName(a), relation, b, objectTypeName(b))
+	}
+}
+
+func (self *asserter) Between(v interface{}, lo interface{}, hi interface{}) {
+	self.tb.Helper()
+	cLo, ok := self.order(v, lo, "greater than or equal to")
+	if !ok {
+		return
+	}
+	cHi, ok := self.order(v, hi, "less than or equal to")
+	if !ok {
+		return
+	}
+	if c, ok := self.order(lo, hi, "less than or equal to"); !ok {
+		return
+	} else if c > 0 {
+		fail(self, "expected lower bound '%v' (%s) to be less than or equal to upper bound '%v' (%s)",
+			lo, objectTypeName(lo), hi, objectTypeName(hi))
+		return
+	}
+	if cLo < 0 || cHi > 0 {
+		fail(self, "expected '%v' (%s) to be between '%v' and '%v' (inclusive)",
+			v, objectTypeName(v), lo, hi)
+	}
+}
+
+func (self *asserter) Sorted(list interface{}) {
+	self.tb.Helper()
+	v := reflect.ValueOf(list)
+	if !isList(v) {
+		fail(self, "expected object '%s' to be sorted, but the object is not one of array or slice", objectTypeName(list))
+		return
+	}
+	for i := 1; i < v.Len(); i++ {
+		x, y := v.Index(i-1).Interface(), v.Index(i).Interface()
+		c, err := compareOrdered(x, y)
+		if err != nil {
+			fail(self, "expected object '%s' to be sorted, but the elements at index %d and %d cannot be ordered: %v",
+				objectTypeName(list), i-1, i, err)
+			return
+		}
+		if c > 0 {
+			fail(self, "expected object '%s' to be sorted, but the element at index %d ('%v') is greater than the element at index %d ('%v') - Value: %s",
+				objectTypeName(list), i-1, x, i, y, truncated(list))
+			return
+		}
+	}
+}
+
+func (self *asserter) SortedFunc(list interface{}, less interface{}) {
+	self.tb.Helper()
+	v := reflect.ValueOf(list)
+	if !isList(v) {
+		fail(self, "expected object '%s' to be sorted, but the object is not one of array or slice", objectTypeName(list))
+		return
+	}
+	fn := reflect.ValueOf(less)
+	if !fn.IsValid() || fn.Kind() != reflect.Func || fn.IsNil() ||
+		fn.Type().NumIn() != 2 || fn.Type().In(0) != fn.Type().In(1) || fn.Type().IsVariadic() ||
+		fn.Type().NumOut() != 1 || fn.Type().Out(0).Kind() != reflect.Bool ||
+		!v.Type().Elem().AssignableTo(fn.Type().In(0)) {
+		fail(self, "expected less to be a function of the form func(a, b %s) bool, but got: %s",
+			v.Type().Elem(), objectTypeName(less))
+		return
+	}
+	for i := 1; i < v.Len(); i++ {
+		x, y := v.Index(i-1), v.Index(i)
+		if fn.Call([]reflect.Value{y, x})[0].Bool() {
+			fail(self, "expected object '%s' to be sorted, but the element at index %d ('%v') is greater than the element at index %d ('%v') - Value: %s",
+				objectTypeName(list), i-1, x, i, y, truncated(list))
+			return
+		}
+	}
+}
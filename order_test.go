@@ -0,0 +1,121 @@
+package is
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+type version struct{ major, minor int }
+
+func (v *version) Compare(o *version) int {
+	if v.major != o.major {
+		return v.major - o.major
+	}
+	return v.minor - o.minor
+}
+
+func TestOrder(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	now := time.Now()
+	assert.Greater(2, 1)
+	assert.Greater(uint64(math.MaxUint64), int64(math.MaxInt64))
+	assert.Greater(1.5, 1)
+	assert.Greater("b", "a")
+	assert.Greater(now.Add(time.Second), now)
+	assert.Greater(2*time.Second, time.Second)
+	assert.Greater(version{1, 2}, version{1, 1})
+	assert.Greater(&version{2, 0}, &version{1, 9})
+	assert.GreaterOrEqual(1, 1)
+	assert.Less(int8(-1), uint(0))
+	assert.LessOrEqual("a", "a")
+	assert.Between(5, 1, 10)
+	assert.Between(1, 1, 1)
+	assert.Between(now, now.Add(-time.Minute), now)
+	assert.Sorted([]int{1, 2, 2, 3})
+	assert.Sorted([]string{})
+	assert.Sorted([]time.Time{now, now.Add(time.Second)})
+	assert.SortedFunc([]int{3, 2, 1}, func(a, b int) bool { return a > b })
+	if hit != 0 {
+		t.Fatalf("expected no failures, but got: %s", msg)
+	}
+
+	assert.Greater(1, 2)
+	if hit != 1 || msg != "expected '1' (int) to be greater than '2' (int)" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.LessOrEqual(3*time.Second, time.Second)
+	if hit != 2 || msg != "expected '3s' (time.Duration) to be less than or equal to '1s' (time.Duration)" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Between(11, 1, 10)
+	if hit != 3 || msg != "expected '11' (int) to be between '1' and '10' (inclusive)" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Less(1, "a")
+	if hit != 4 || msg != "expected '1' (int) to be less than 'a' (string), but 'int' cannot be ordered against 'string'" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Less(complex(1, 0), 2)
+	assert.Less(math.NaN(), 2)
+	assert.Less(nil, 2)
+	assert.Less(big.NewInt(1), big.NewInt(2))
+	if hit != 8 || !strings.HasSuffix(msg, "but '*big.Int' is not an ordered type") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Sorted([]int{1, 3, 2, 4})
+	if hit != 9 || msg != "expected object '[]int' to be sorted, but the element at index 1 ('3') is greater than the element at index 2 ('2') - Value: [1 3 2 4]" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.SortedFunc([]int{1, 2}, func(a, b int) bool { return a > b })
+	if hit != 10 || !strings.Contains(msg, "the element at index 0 ('1') is greater than the element at index 1 ('2')") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Sorted(42)
+	assert.Sorted([]interface{}{1, "a"})
+	assert.SortedFunc([]int{1}, func(a, b string) bool { return a < b })
+	if hit != 13 || msg != "expected less to be a function of the form func(a, b int) bool, but got: func(string, string) bool" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	strict := New(t, Strict())
+	strict.Greater(int64(2), 1)
+	if hit != 14 || !strings.HasSuffix(msg, "but their types differ - Types: int64 != int") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.SortedFunc([]int{1}, nil)
+	if hit != 15 || !strings.HasPrefix(msg, "expected less to be a function of the form func(a, b int) bool, but got: ") {
+		t.Fatalf("expected failure for nil less, but got: %s", msg)
+	}
+	assert.SortedFunc([]int{1}, (func(a, b int) bool)(nil))
+	if hit != 16 {
+		t.Fatalf("expected failure for nil less, but got: %s", msg)
+	}
+
+	assert.Between(5, 10, 1)
+	assert.Between(11, 10, 1)
+	if hit != 18 || msg != "expected lower bound '10' (int) to be less than or equal to upper bound '1' (int)" {
+		t.Fatalf("expected failure for an inverted range, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
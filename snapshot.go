@@ -0,0 +1,330 @@
+package is
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// importPath is the import path of this package, used to find the name under
+// which it is imported when rewriting snapshots.
+const importPath = "github.com/tylerb/is/v3"
+
+// SnapshotOption configures a call to Snapshot.
+type SnapshotOption struct {
+	inline *string
+}
+
+// Inline provides the stored snapshot to compare against. It is written into
+// the test source by Snapshot when tests are run in update mode, and should
+// not normally be edited by hand.
+func Inline(snapshot string) SnapshotOption {
+	return SnapshotOption{inline: &snapshot}
+}
+
+// snapshotFile holds the original source of a test file along with the
+// snapshots to write into it. Line numbers reported by the runtime refer to
+// the original source, so every update is applied to it afresh.
+type snapshotFile struct {
+	src     []byte
+	updates map[int]string
+}
+
+// snapshotFiles holds the test files updated during this run.
+var snapshotFiles = struct {
+	sync.Mutex
+	files map[string]*snapshotFile
+}{files: make(map[string]*snapshotFile)}
+
+func (self *asserter) Snapshot(actual interface{}, opts ...SnapshotOption) {
+	self.tb.Helper()
+	var expected *string
+	for _, opt := range opts {
+		if opt.inline != nil {
+			expected = opt.inline
+		}
+	}
+
+	text := prettyPrint(actual)
+	if expected != nil && strings.TrimSpace(*expected) == strings.TrimSpace(text) {
+		return
+	}
+
+	if updating() {
+		_, file, line, ok := runtime.Caller(1)
+		if !ok {
+			fail(self, "unable to find the caller of Snapshot to update it")
+			return
+		}
+		if err := updateSnapshot(file, line, text); err != nil {
+			fail(self, "unable to update snapshot in %s:%d: %v", file, line, err)
+		}
+		return
+	}
+
+	if expected == nil {
+		fail(self, "snapshot of '%s' is missing; run tests with -is.update or %s=1 to create it - Value:\n%s",
+			objectTypeName(actual), updateEnv, text)
+		return
+	}
+	fail(self, "snapshot of '%s' does not match; run tests with -is.update or %s=1 to update it - Diff:\n%s",
+		objectTypeName(actual), updateEnv,
+		unifiedDiff(strings.TrimSpace(text)+"\n", strings.TrimSpace(*expected)+"\n", diffContext))
+}
+
+// updateSnapshot records text as the snapshot for the call to Snapshot on
+// the given line of file, and rewrites the file with every snapshot recorded
+// for it so far.
+func updateSnapshot(file string, line int, text string) error {
+	snapshotFiles.Lock()
+	defer snapshotFiles.Unlock()
+
+	sf, ok := snapshotFiles.files[file]
+	if !ok {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		sf = &snapshotFile{src: src, updates: make(map[int]string)}
+		snapshotFiles.files[file] = sf
+	}
+	sf.updates[line] = text
+
+	out, err := rewriteSnapshots(file, sf.src, sf.updates)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, out, 0644)
+}
+
+// rewriteSnapshots parses src and sets the Inline argument of the calls to
+// Snapshot found on the lines in updates, returning the formatted result.
+func rewriteSnapshots(file string, src []byte, updates map[int]string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	qualifier := packageQualifier(f)
+
+	found := make(map[int]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || !isCallTo(call, "Snapshot") || len(call.Args) == 0 {
+			return true
+		}
+		start := fset.Position(call.Pos()).Line
+		end := fset.Position(call.End()).Line
+		for line, text := range updates {
+			if line < start || line > end || found[line] {
+				continue
+			}
+			found[line] = true
+			setInline(call, text, qualifier)
+		}
+		return true
+	})
+	for line := range updates {
+		if !found[line] {
+			return nil, fmt.Errorf("no call to Snapshot found on line %d", line)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := format.Node(&b, fset, f); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// setInline replaces the argument of the Inline option passed to call with
+// text, adding the option if it is not present.
+func setInline(call *ast.CallExpr, text string, qualifier string) {
+	lit := &ast.BasicLit{Kind: token.STRING, Value: stringLiteral(text)}
+	for _, arg := range call.Args[1:] {
+		if inline, ok := arg.(*ast.CallExpr); ok && isCallTo(inline, "Inline") {
+			inline.Args = []ast.Expr{lit}
+			return
+		}
+	}
+	var fun ast.Expr = ast.NewIdent("Inline")
+	if qualifier != "" {
+		fun = &ast.SelectorExpr{X: ast.NewIdent(qualifier), Sel: ast.NewIdent("Inline")}
+	}
+	call.Args = append(call.Args, &ast.CallExpr{Fun: fun, Args: []ast.Expr{lit}})
+}
+
+// isCallTo reports whether call is a call to a function or method called
+// name.
+func isCallTo(call *ast.CallExpr, name string) bool {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name == name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name == name
+	}
+	return false
+}
+
+// packageQualifier returns the name under which this package is imported by
+// f, or an empty string if f belongs to this package.
+func packageQualifier(f *ast.File) string {
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || path != importPath {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return "is"
+	}
+	return ""
+}
+
+// stringLiteral returns a Go string literal for s, preferring a raw string
+// literal so that multi-line snapshots remain readable.
+func stringLiteral(s string) string {
+	if !strings.Contains(s, "`") && !strings.Contains(s, "\r") {
+		if strings.Contains(s, "\n") {
+			return "`\n" + s + "\n`"
+		}
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// prettyPrint returns a deterministic, multi-line Go-syntax representation
+// of o for use in snapshots. Strings are returned as is.
+func prettyPrint(o interface{}) string {
+	if s, ok := o.(string); ok {
+		return s
+	}
+	p := &prettyPrinter{visited: make(map[uintptr]bool)}
+	p.print(addressable(reflect.ValueOf(o)), 0)
+	return p.b.String()
+}
+
+// prettyPrinter writes values in an indented, Go-like syntax.
+type prettyPrinter struct {
+	b       bytes.Buffer
+	visited map[uintptr]bool
+}
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+func (p *prettyPrinter) print(v reflect.Value, depth int) {
+	if !v.IsValid() {
+		p.b.WriteString("nil")
+		return
+	}
+
+	// Types with a String method, such as time.Time, usually hold
+	// unexported state that is not meaningful in a snapshot.
+	if v.Kind() == reflect.Struct && v.Type().Implements(stringerType) {
+		if av, ok := accessible(v); ok {
+			fmt.Fprintf(&p.b, "%s(%q)", v.Type(), av.Interface().(fmt.Stringer).String())
+			return
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			fmt.Fprintf(&p.b, "(%s)(nil)", v.Type())
+			return
+		}
+		if p.visited[v.Pointer()] {
+			p.b.WriteString("<cycle>")
+			return
+		}
+		p.visited[v.Pointer()] = true
+		defer delete(p.visited, v.Pointer())
+		p.b.WriteString("&")
+		p.print(v.Elem(), depth)
+	case reflect.Interface:
+		if v.IsNil() {
+			p.b.WriteString("nil")
+			return
+		}
+		p.print(v.Elem(), depth)
+	case reflect.Struct:
+		fmt.Fprintf(&p.b, "%s{", v.Type())
+		if v.NumField() == 0 {
+			p.b.WriteString("}")
+			return
+		}
+		p.b.WriteString("\n")
+		for i := 0; i < v.NumField(); i++ {
+			p.indent(depth + 1)
+			fmt.Fprintf(&p.b, "%s: ", v.Type().Field(i).Name)
+			p.print(v.Field(i), depth+1)
+			p.b.WriteString(",\n")
+		}
+		p.indent(depth)
+		p.b.WriteString("}")
+	case reflect.Map:
+		if v.IsNil() {
+			fmt.Fprintf(&p.b, "%s(nil)", v.Type())
+			return
+		}
+		fmt.Fprintf(&p.b, "%s{", v.Type())
+		if v.Len() == 0 {
+			p.b.WriteString("}")
+			return
+		}
+		p.b.WriteString("\n")
+		keys := v.MapKeys()
+		sortValues(keys)
+		for _, k := range keys {
+			p.indent(depth + 1)
+			p.print(k, depth+1)
+			p.b.WriteString(": ")
+			p.print(addressable(v.MapIndex(k)), depth+1)
+			p.b.WriteString(",\n")
+		}
+		p.indent(depth)
+		p.b.WriteString("}")
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			fmt.Fprintf(&p.b, "%s(nil)", v.Type())
+			return
+		}
+		fmt.Fprintf(&p.b, "%s{", v.Type())
+		if v.Len() == 0 {
+			p.b.WriteString("}")
+			return
+		}
+		p.b.WriteString("\n")
+		for i := 0; i < v.Len(); i++ {
+			p.indent(depth + 1)
+			p.print(v.Index(i), depth+1)
+			p.b.WriteString(",\n")
+		}
+		p.indent(depth)
+		p.b.WriteString("}")
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		// Addresses are not stable between runs, so only the type and
+		// whether the value is nil are printed.
+		if v.IsNil() {
+			fmt.Fprintf(&p.b, "(%s)(nil)", v.Type())
+			return
+		}
+		fmt.Fprintf(&p.b, "(%s)(...)", v.Type())
+	default:
+		p.b.WriteString(formatValue(v))
+	}
+}
+
+func (p *prettyPrinter) indent(depth int) {
+	p.b.WriteString(strings.Repeat("\t", depth))
+}
@@ -0,0 +1,148 @@
+package is
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type snapshotUser struct {
+	Name   string
+	Tags   []string
+	Meta   map[string]int
+	At     time.Time
+	Next   *snapshotUser
+	hidden int
+}
+
+func TestSnapshot(t *testing.T) {
+	assert := New(t)
+
+	user := &snapshotUser{
+		Name:   "bob",
+		Tags:   []string{"a"},
+		Meta:   map[string]int{"b": 2, "a": 1},
+		At:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		hidden: 3,
+	}
+	assert.Snapshot(user, Inline(`
+&is.snapshotUser{
+	Name: "bob",
+	Tags: []string{
+		"a",
+	},
+	Meta: map[string]int{
+		"a": 1,
+		"b": 2,
+	},
+	At: time.Time("2020-01-01 00:00:00 +0000 UTC"),
+	Next: (*is.snapshotUser)(nil),
+	hidden: 3,
+}
+`))
+	assert.Snapshot("plain\ntext", Inline(`plain
+text`))
+
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	assert.Snapshot([]int{1, 2}, Inline(`[]int{
+	1,
+	3,
+}`))
+	if !strings.Contains(msg, " \t1,\n-\t2,\n+\t3,\n") {
+		t.Fatalf("expected snapshot diff, but got: %s", msg)
+	}
+
+	assert.Snapshot(1)
+	if !strings.HasPrefix(msg, "snapshot of 'int' is missing") {
+		t.Fatalf("expected missing snapshot failure, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
+
+func TestRewriteSnapshots(t *testing.T) {
+	src := `package example
+
+import (
+	"testing"
+
+	"github.com/tylerb/is/v3"
+)
+
+func TestExample(t *testing.T) {
+	assert := is.New(t)
+	assert.Snapshot(1)
+	assert.Snapshot(
+		"a\nb",
+		is.Inline(` + "`old`" + `),
+	)
+}
+`
+	expected := `package example
+
+import (
+	"testing"
+
+	"github.com/tylerb/is/v3"
+)
+
+func TestExample(t *testing.T) {
+	assert := is.New(t)
+	assert.Snapshot(1, is.Inline(` + "`1`" + `))
+	assert.Snapshot(
+		"a\nb",
+		is.Inline(` + "`\na\nb\n`" + `),
+	)
+}
+`
+	out, err := rewriteSnapshots("example_test.go", []byte(src), map[int]string{11: "1", 14: "a\nb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != expected {
+		t.Fatalf("expected rewritten source:\n%s\nbut got:\n%s", expected, out)
+	}
+
+	_, err = rewriteSnapshots("example_test.go", []byte(src), map[int]string{3: "1"})
+	if err == nil || !strings.Contains(err.Error(), "no call to Snapshot found on line 3") {
+		t.Fatalf("expected error for a line without a call to Snapshot, but got: %v", err)
+	}
+}
+
+func TestUpdateSnapshot(t *testing.T) {
+	dir, err := os.MkdirTemp("", "is-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := "package is\n\nfunc f(assert Asserter) {\n\tassert.Snapshot(1)\n\tassert.Snapshot(2)\n}\n"
+	path := filepath.Join(dir, "example_test.go")
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both updates refer to lines in the original source, even though the
+	// first one changes the file.
+	if err := updateSnapshot(path, 4, "a\nb"); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateSnapshot(path, 5, "2"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "package is\n\nfunc f(assert Asserter) {\n\tassert.Snapshot(1, Inline(`\na\nb\n`))\n\tassert.Snapshot(2, Inline(`2`))\n}\n"
+	if string(out) != expected {
+		t.Fatalf("expected updated source:\n%s\nbut got:\n%s", expected, out)
+	}
+}
@@ -0,0 +1,630 @@
+package is
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// diffContext is the number of unchanged lines printed around each change
+// in a unified diff.
+const diffContext = 3
+
+// lineEdit is a single step in a line-based edit script. kind is ' ' for a
+// line present in both texts, '-' for a line only in the actual text and '+'
+// for a line only in the expected text.
+type lineEdit struct {
+	kind byte
+	line string
+}
+
+// splitLines splits s into lines, without their line terminators.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// maxDiffCells bounds the product of the numbers of lines diffLines
+// compares, after removing their common prefix and suffix. Beyond it, the
+// texts are reported as entirely replaced rather than spending quadratic time
+// looking for their common lines.
+const maxDiffCells = 1 << 25
+
+// diffLines returns the shortest edit script that transforms a into b, using
+// the linear space variant of the Myers difference algorithm. If the lines
+// that differ are too many to compare, all of them are removed and added.
+func diffLines(a, b []string) []lineEdit {
+	var edits []lineEdit
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	edits = appendEdits(edits, ' ', a[:prefix])
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(ma)*len(mb) > maxDiffCells {
+		edits = appendEdits(edits, '-', ma)
+		edits = appendEdits(edits, '+', mb)
+	} else {
+		size := (len(ma)+len(mb)+1)/2 + 1
+		d := &lineDiffer{
+			forward:  make([]int, 2*size+1),
+			backward: make([]int, 2*size+1),
+			offset:   size,
+			edits:    edits,
+		}
+		d.compare(ma, mb)
+		edits = d.edits
+	}
+	return appendEdits(edits, ' ', a[len(a)-suffix:])
+}
+
+// appendEdits appends an edit of the given kind for each of lines.
+func appendEdits(edits []lineEdit, kind byte, lines []string) []lineEdit {
+	for _, line := range lines {
+		edits = append(edits, lineEdit{kind: kind, line: line})
+	}
+	return edits
+}
+
+// lineDiffer holds the state of diffLines. forward and backward hold the
+// furthest reaching paths on each diagonal, searching from the start and the
+// end of the texts, and are reused at every level of recursion.
+type lineDiffer struct {
+	forward  []int
+	backward []int
+	offset   int
+	edits    []lineEdit
+}
+
+// compare appends the shortest edit script that transforms a into b.
+func (d *lineDiffer) compare(a, b []string) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	d.edits = appendEdits(d.edits, ' ', a[:prefix])
+	a, b = a[prefix:], b[prefix:]
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		d.edits = appendEdits(d.edits, '+', b)
+	case len(b) == 0:
+		d.edits = appendEdits(d.edits, '-', a)
+	default:
+		// Both texts are non-empty and differ at both ends, so at least two
+		// edits are needed, and the halves on either side of the middle
+		// snake are strictly smaller problems.
+		x, y, u, v := d.middleSnake(a, b)
+		d.compare(a[:x], b[:y])
+		d.edits = appendEdits(d.edits, ' ', a[x:u])
+		d.compare(a[u:], b[v:])
+	}
+	d.edits = appendEdits(d.edits, ' ', common)
+}
+
+// middleSnake returns the middle snake of a shortest edit script that
+// transforms a into b, as the diagonal run of equal lines from (x, y) to
+// (u, v), found by searching from both ends of the texts at once.
+func (d *lineDiffer) middleSnake(a, b []string) (x, y, u, v int) {
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	vf, vb, off := d.forward, d.backward, d.offset
+	vf[off+1], vb[off+1] = 0, 0
+	for e := 0; e <= (n+m+1)/2; e++ {
+		for k := -e; k <= e; k += 2 {
+			var x int
+			if k == -e || (k != e && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			} else {
+				x = vf[off+k-1] + 1
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			vf[off+k] = x
+			if kb := delta - k; odd && kb >= -(e-1) && kb <= e-1 && x+vb[off+kb] >= n {
+				return x0, y0, x, y
+			}
+		}
+		// The backward search runs over the reversed texts, where diagonal k
+		// corresponds to diagonal delta-k of the forward search.
+		for k := -e; k <= e; k += 2 {
+			var x int
+			if k == -e || (k != e && vb[off+k-1] < vb[off+k+1]) {
+				x = vb[off+k+1]
+			} else {
+				x = vb[off+k-1] + 1
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			vb[off+k] = x
+			if kf := delta - k; !odd && kf >= -e && kf <= e && x+vf[off+kf] >= n {
+				return n - x, m - y, n - x0, m - y0
+			}
+		}
+	}
+	panic("is: no middle snake found")
+}
+
+// unifiedDiff returns a unified diff of the lines of actual and expected,
+// with context unchanged lines around each change. It returns an empty
+// string if the texts have the same lines.
+func unifiedDiff(actual string, expected string, context int) string {
+	return formatUnified(diffLines(splitLines(actual), splitLines(expected)), context)
+}
+
+// textDiff returns a unified diff of the lines of actual and expected, as
+// unifiedDiff does, for use in failure messages. White space and
+// non-printable runes in changed lines are made visible, and when a changed
+// line can be paired with its replacement, the runes that differ between
+// them are highlighted with [-actual-] and {+expected+}.
+func textDiff(actual string, expected string, context int) string {
+	edits := diffLines(splitLines(actual), splitLines(expected))
+	highlightEdits(edits)
+	return formatUnified(edits, context)
+}
+
+// formatUnified renders an edit script as the hunks of a unified diff, with
+// context unchanged lines around each change. A negative context is treated
+// as 0.
+func formatUnified(edits []lineEdit, context int) string {
+	if context < 0 {
+		context = 0
+	}
+	var b bytes.Buffer
+	b.WriteString("--- actual\n+++ expected\n")
+	changed := false
+	for start := 0; start < len(edits); {
+		// Find the next change.
+		for start < len(edits) && edits[start].kind == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+		changed = true
+
+		// Extend the hunk until the gap between changes is larger than
+		// twice the context.
+		end := start
+		for i := start; i < len(edits); i++ {
+			if edits[i].kind != ' ' {
+				end = i + 1
+				continue
+			}
+			if i-end >= 2*context {
+				break
+			}
+		}
+		lo := start - context
+		if lo < 0 {
+			lo = 0
+		}
+		hi := end + context
+		if hi > len(edits) {
+			hi = len(edits)
+		}
+
+		aLine, eLine := 1, 1
+		for _, e := range edits[:lo] {
+			if e.kind != '+' {
+				aLine++
+			}
+			if e.kind != '-' {
+				eLine++
+			}
+		}
+		aCount, eCount := 0, 0
+		for _, e := range edits[lo:hi] {
+			if e.kind != '+' {
+				aCount++
+			}
+			if e.kind != '-' {
+				eCount++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(aLine, aCount), hunkRange(eLine, eCount))
+		for _, e := range edits[lo:hi] {
+			b.WriteByte(e.kind)
+			b.WriteString(e.line)
+			b.WriteByte('\n')
+		}
+		start = hi
+	}
+	if !changed {
+		return ""
+	}
+	return b.String()
+}
+
+// hunkRange formats the range of a hunk in a unified diff.
+func hunkRange(line, count int) string {
+	if count == 0 {
+		line--
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", line)
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
+
+// highlightEdits rewrites the changed lines of edits for display. Each run
+// of changed lines is split into removed and added lines, which are paired
+// in order; the runes between the common prefix and suffix of each pair are
+// highlighted, unless the pair has nothing in common.
+func highlightEdits(edits []lineEdit) {
+	for start := 0; start < len(edits); start++ {
+		if edits[start].kind == ' ' {
+			continue
+		}
+		var removed, added []int
+		end := start
+		for ; end < len(edits) && edits[end].kind != ' '; end++ {
+			if edits[end].kind == '-' {
+				removed = append(removed, end)
+			} else {
+				added = append(added, end)
+			}
+		}
+
+		highlighted := make(map[int]bool)
+		for i := 0; i < len(removed) && i < len(added); i++ {
+			r, a := removed[i], added[i]
+			aLine, eLine := edits[r].line, edits[a].line
+			prefix, suffix := commonAffixes(aLine, eLine)
+			if prefix == 0 && suffix == 0 {
+				continue
+			}
+			edits[r].line = visibleLine(aLine, prefix, len(aLine)-suffix, "[-", "-]")
+			edits[a].line = visibleLine(eLine, prefix, len(eLine)-suffix, "{+", "+}")
+			highlighted[r], highlighted[a] = true, true
+		}
+		for i := start; i < end; i++ {
+			if !highlighted[i] {
+				edits[i].line = visibleLine(edits[i].line, 0, 0, "", "")
+			}
+		}
+		start = end
+	}
+}
+
+// commonAffixes returns the length in bytes of the longest common prefix and
+// suffix of a and b, on rune boundaries and without overlapping.
+func commonAffixes(a string, b string) (prefix int, suffix int) {
+	for prefix < len(a) && prefix < len(b) {
+		ar, n := utf8.DecodeRuneInString(a[prefix:])
+		br, _ := utf8.DecodeRuneInString(b[prefix:])
+		if ar != br {
+			break
+		}
+		prefix += n
+	}
+	for suffix < len(a)-prefix && suffix < len(b)-prefix {
+		ar, n := utf8.DecodeLastRuneInString(a[:len(a)-suffix])
+		br, m := utf8.DecodeLastRuneInString(b[:len(b)-suffix])
+		if ar != br || n != m {
+			break
+		}
+		suffix += n
+	}
+	return prefix, suffix
+}
+
+// visibleLine returns line with trailing spaces, tabs, carriage returns and
+// non-printable runes replaced by visible markers. If lo is less than hi,
+// the bytes between them are wrapped in open and close.
+func visibleLine(line string, lo int, hi int, open string, close string) string {
+	trailing := len(strings.TrimRight(line, " \t\r"))
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		if lo < hi && i == hi {
+			b.WriteString(close)
+		}
+		if lo < hi && i == lo {
+			b.WriteString(open)
+		}
+		r, n := utf8.DecodeRuneInString(line[i:])
+		switch {
+		case r == utf8.RuneError && n == 1:
+			fmt.Fprintf(&b, "\\x%02x", line[i])
+		case r == ' ' && i >= trailing:
+			b.WriteString("·")
+		case r == ' ':
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteString("→")
+		case r == '\r':
+			b.WriteString("␍")
+		case !unicode.IsPrint(r):
+			q := strconv.QuoteRuneToASCII(r)
+			b.WriteString(q[1 : len(q)-1])
+		default:
+			b.WriteRune(r)
+		}
+		i += n
+	}
+	if lo < hi && hi == len(line) {
+		b.WriteString(close)
+	}
+	return b.String()
+}
+
+// regexps caches compiled regular expressions by pattern, so that patterns
+// used in loops or shared between tests are compiled once.
+var regexps sync.Map
+
+// compileRegexp returns the compiled form of pattern.
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexps.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexps.Store(pattern, re)
+	return re, nil
+}
+
+// textOf returns the text of o, which may be a string, a []byte or a
+// fmt.Stringer. It returns false if o is none of these.
+func textOf(o interface{}) (string, bool) {
+	switch v := o.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	case fmt.Stringer:
+		return v.String(), true
+	}
+	if v := reflect.ValueOf(o); v.Kind() == reflect.String {
+		return v.String(), true
+	}
+	return "", false
+}
+
+// excerptWidth is the number of bytes printed on either side of the
+// position marked by excerpt.
+const excerptWidth = 30
+
+// excerpt returns the text of s around byte offset pos as a quoted string,
+// followed by a line with a caret under the character at pos.
+func excerpt(s string, pos int) string {
+	start, end := pos-excerptWidth, pos+excerptWidth
+	prefix, suffix := "", ""
+	if start <= 0 {
+		start = 0
+	} else {
+		for start < pos && !utf8.RuneStart(s[start]) {
+			start++
+		}
+		prefix = "..."
+	}
+	if end >= len(s) {
+		end = len(s)
+	} else {
+		for end > pos && !utf8.RuneStart(s[end]) {
+			end--
+		}
+		suffix = "..."
+	}
+	before := strconv.QuoteToASCII(s[start:pos])
+	after := strconv.QuoteToASCII(s[pos:end])
+	line := prefix + before[:len(before)-1] + after[1:] + suffix
+	return line + "\n" + strings.Repeat(" ", len(prefix)+len(before)-1) + "^"
+}
+
+// mismatch describes the position at which actual and expected differ, at
+// byte offsets ai and ei respectively, with a caret-marked excerpt of each.
+func mismatch(actual string, expected string, ai int, ei int) string {
+	a := strings.Split(excerpt(actual, ai), "\n")
+	e := strings.Split(excerpt(expected, ei), "\n")
+	return fmt.Sprintf(" - Mismatch at byte offset %d:\n\tactual:   %s\n\t          %s\n\texpected: %s\n\t          %s",
+		ai, a[0], a[1], e[0], e[1])
+}
+
+// firstMismatch returns the byte offsets in a and b of the first rune at
+// which they differ, comparing runes with equal.
+func firstMismatch(a string, b string, equal func(x, y rune) bool) (int, int) {
+	ai, bi := 0, 0
+	for ai < len(a) && bi < len(b) {
+		ar, an := utf8.DecodeRuneInString(a[ai:])
+		br, bn := utf8.DecodeRuneInString(b[bi:])
+		if !equal(ar, br) {
+			break
+		}
+		ai += an
+		bi += bn
+	}
+	return ai, bi
+}
+
+// lastMismatch returns the byte offsets in a and b of the last rune at which
+// they differ when aligned at their ends. If one is a suffix of the other,
+// the offsets of the start of the shorter string's alignment are returned.
+func lastMismatch(a string, b string) (int, int) {
+	ai, bi := len(a), len(b)
+	for ai > 0 && bi > 0 {
+		ar, an := utf8.DecodeLastRuneInString(a[:ai])
+		br, bn := utf8.DecodeLastRuneInString(b[:bi])
+		if ar != br {
+			return ai - an, bi - bn
+		}
+		ai -= an
+		bi -= bn
+	}
+	return ai, bi
+}
+
+func sameRune(x, y rune) bool {
+	return x == y
+}
+
+func foldedRune(x, y rune) bool {
+	if x == y {
+		return true
+	}
+	for r := unicode.SimpleFold(x); r != x; r = unicode.SimpleFold(r) {
+		if r == y {
+			return true
+		}
+	}
+	return false
+}
+
+// collapseSpace replaces every run of white space in s with a single space,
+// and removes leading and trailing white space.
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// normalizeLineEndings replaces \r\n and \r line endings in s with \n.
+func normalizeLineEndings(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\r", "\n")
+}
+
+// texts returns the text of actual and expected, failing if either is not a
+// string, []byte or fmt.Stringer.
+func (self *asserter) texts(actual interface{}, expected interface{}) (string, string, bool) {
+	self.tb.Helper()
+	a, ok := textOf(actual)
+	if !ok {
+		fail(self, "expected object '%s' to be one of string, []byte or fmt.Stringer", objectTypeName(actual))
+		return "", "", false
+	}
+	e, ok := textOf(expected)
+	if !ok {
+		fail(self, "expected object '%s' to be one of string, []byte or fmt.Stringer", objectTypeName(expected))
+		return "", "", false
+	}
+	return a, e, true
+}
+
+func (self *asserter) Match(pattern string, s interface{}) {
+	self.tb.Helper()
+	re, err := compileRegexp(pattern)
+	if err != nil {
+		fail(self, "invalid regular expression %q: %v", pattern, err)
+		return
+	}
+	text, _, ok := self.texts(s, "")
+	if !ok {
+		return
+	}
+	if !re.MatchString(text) {
+		fail(self, "expected %q to match %q", text, pattern)
+	}
+}
+
+func (self *asserter) NotMatch(pattern string, s interface{}) {
+	self.tb.Helper()
+	re, err := compileRegexp(pattern)
+	if err != nil {
+		fail(self, "invalid regular expression %q: %v", pattern, err)
+		return
+	}
+	text, _, ok := self.texts(s, "")
+	if !ok {
+		return
+	}
+	if loc := re.FindStringIndex(text); loc != nil {
+		fail(self, "expected %q not to match %q, but it matched %q at byte offset %d:\n\t%s",
+			text, pattern, text[loc[0]:loc[1]], loc[0],
+			strings.Replace(excerpt(text, loc[0]), "\n", "\n\t", 1))
+	}
+}
+
+func (self *asserter) HasPrefix(s interface{}, prefix string) {
+	self.tb.Helper()
+	text, _, ok := self.texts(s, "")
+	if !ok {
+		return
+	}
+	if !strings.HasPrefix(text, prefix) {
+		ai, ei := firstMismatch(text, prefix, sameRune)
+		fail(self, "expected %q to have prefix %q%s", text, prefix, mismatch(text, prefix, ai, ei))
+	}
+}
+
+func (self *asserter) HasSuffix(s interface{}, suffix string) {
+	self.tb.Helper()
+	text, _, ok := self.texts(s, "")
+	if !ok {
+		return
+	}
+	if !strings.HasSuffix(text, suffix) {
+		ai, ei := lastMismatch(text, suffix)
+		fail(self, "expected %q to have suffix %q%s", text, suffix, mismatch(text, suffix, ai, ei))
+	}
+}
+
+func (self *asserter) EqualFold(actual interface{}, expected interface{}) {
+	self.tb.Helper()
+	a, e, ok := self.texts(actual, expected)
+	if !ok {
+		return
+	}
+	if !strings.EqualFold(a, e) {
+		ai, ei := firstMismatch(a, e, foldedRune)
+		fail(self, "actual value %q should be equal to expected value %q, ignoring case%s",
+			a, e, mismatch(a, e, ai, ei))
+	}
+}
+
+func (self *asserter) EqualIgnoreSpace(actual interface{}, expected interface{}) {
+	self.tb.Helper()
+	a, e, ok := self.texts(actual, expected)
+	if !ok {
+		return
+	}
+	a, e = collapseSpace(a), collapseSpace(e)
+	if a != e {
+		ai, ei := firstMismatch(a, e, sameRune)
+		fail(self, "actual value %q should be equal to expected value %q, ignoring white space%s",
+			a, e, mismatch(a, e, ai, ei))
+	}
+}
+
+func (self *asserter) EqualIgnoreLineEndings(actual interface{}, expected interface{}) {
+	self.tb.Helper()
+	a, e, ok := self.texts(actual, expected)
+	if !ok {
+		return
+	}
+	a, e = normalizeLineEndings(a), normalizeLineEndings(e)
+	if a != e {
+		ai, ei := firstMismatch(a, e, sameRune)
+		fail(self, "actual value %q should be equal to expected value %q, ignoring line endings%s",
+			a, e, mismatch(a, e, ai, ei))
+	}
+}
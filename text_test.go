@@ -0,0 +1,339 @@
+package is
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"net"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		actual   string
+		expected string
+		diff     string
+	}{
+		{
+			actual:   "a\nb\nc\n",
+			expected: "a\nb\nc\n",
+			diff:     "",
+		},
+		{
+			actual:   "a\nb\nc\n",
+			expected: "a\nx\nc\n",
+			diff:     "--- actual\n+++ expected\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			actual:   "",
+			expected: "a\n",
+			diff:     "--- actual\n+++ expected\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			actual:   "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			expected: "x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny\n",
+			diff: "--- actual\n+++ expected\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+y\n",
+		},
+		{
+			actual:   "1\n2\n3\n4\n5\n6\n7\n8\n",
+			expected: "x\n2\n3\n4\n5\n6\n7\ny\n",
+			diff: "--- actual\n+++ expected\n" +
+				"@@ -1,8 +1,8 @@\n-1\n+x\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+y\n",
+		},
+	}
+	for i, test := range tests {
+		d := unifiedDiff(test.actual, test.expected, diffContext)
+		if d != test.diff {
+			t.Fatalf("(test #%d) expected diff:\n%s\nbut got:\n%s", i, test.diff, d)
+		}
+	}
+}
+
+// checkEdits fails t unless edits reproduces a and b with the given number
+// of changes.
+func checkEdits(t *testing.T, a, b []string, edits []lineEdit, changes int) {
+	t.Helper()
+	var gotA, gotB []string
+	n := 0
+	for _, e := range edits {
+		if e.kind != '+' {
+			gotA = append(gotA, e.line)
+		}
+		if e.kind != '-' {
+			gotB = append(gotB, e.line)
+		}
+		if e.kind != ' ' {
+			n++
+		}
+	}
+	if strings.Join(gotA, " ") != strings.Join(a, " ") || strings.Join(gotB, " ") != strings.Join(b, " ") {
+		t.Fatalf("edit script for %q and %q does not reproduce its inputs: %v", a, b, edits)
+	}
+	if n != changes {
+		t.Fatalf("expected the shortest edit script for %q and %q of %d changes, but got %d", a, b, changes, n)
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b.
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestDiffLines(t *testing.T) {
+	a := strings.Split("a b c a b b a", " ")
+	b := strings.Split("c b a b a c", " ")
+	checkEdits(t, a, b, diffLines(a, b), 5)
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a := make([]string, r.Intn(12))
+		for j := range a {
+			a[j] = string(rune('a' + r.Intn(3)))
+		}
+		b := make([]string, r.Intn(12))
+		for j := range b {
+			b[j] = string(rune('a' + r.Intn(3)))
+		}
+		checkEdits(t, a, b, diffLines(a, b), len(a)+len(b)-2*lcsLength(a, b))
+	}
+}
+
+func TestDiffLinesLarge(t *testing.T) {
+	a := make([]string, 5000)
+	b := make([]string, 5000)
+	for i := range a {
+		a[i] = fmt.Sprintf("a%d", i)
+		b[i] = fmt.Sprintf("b%d", i)
+	}
+	b[2500] = a[2500]
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	edits := diffLines(a, b)
+	runtime.ReadMemStats(&after)
+	checkEdits(t, a, b, edits, 9998)
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 16<<20 {
+		t.Fatalf("expected diffLines to use linear space, but it allocated %d bytes", alloc)
+	}
+
+	// Beyond maxDiffCells, the differing lines are removed and added as a
+	// whole, after the common prefix and suffix.
+	a = make([]string, 6002)
+	b = make([]string, 6002)
+	for i := range a {
+		a[i] = fmt.Sprintf("a%d", i)
+		b[i] = fmt.Sprintf("b%d", i)
+	}
+	a[0], b[0] = "same", "same"
+	a[6001], b[6001] = "same", "same"
+	edits = diffLines(a, b)
+	checkEdits(t, a, b, edits, 12000)
+	if edits[1].kind != '-' || edits[6000].kind != '-' || edits[6001].kind != '+' {
+		t.Fatalf("expected the texts to be removed and added as a whole, but got: %v", edits[:3])
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		s        string
+		pos      int
+		expected string
+	}{
+		{s: "hello", pos: 0, expected: "\"hello\"\n ^"},
+		{s: "hello", pos: 4, expected: "\"hello\"\n     ^"},
+		{s: "hello", pos: 5, expected: "\"hello\"\n      ^"},
+		{s: "a\tb", pos: 2, expected: "\"a\\tb\"\n    ^"},
+		{s: "héllo", pos: 3, expected: "\"h\\u00e9llo\"\n        ^"},
+		{
+			s:        strings.Repeat("a", 40) + "X" + strings.Repeat("b", 40),
+			pos:      40,
+			expected: "...\"" + strings.Repeat("a", 30) + "X" + strings.Repeat("b", 29) + "\"...\n" + strings.Repeat(" ", 34) + "^",
+		},
+	}
+	for _, test := range tests {
+		if e := excerpt(test.s, test.pos); e != test.expected {
+			t.Fatalf("expected excerpt of %q at %d to be:\n%s\nbut got:\n%s", test.s, test.pos, test.expected, e)
+		}
+	}
+}
+
+func TestStringAssertions(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	assert.Match(`^\d+$`, "123")
+	assert.Match(`^\d+$`, []byte("123"))
+	assert.Match(`^127\.`, net.IPv4(127, 0, 0, 1))
+	assert.NotMatch(`\d`, "abc")
+	assert.HasPrefix("hello world", "hello")
+	assert.HasPrefix(bytes.NewBufferString("hello"), "he")
+	assert.HasSuffix("hello world", "world")
+	assert.EqualFold("Hello", []byte("hELLO"))
+	assert.EqualIgnoreSpace("  a\tb\n\nc ", "a b c")
+	assert.EqualIgnoreLineEndings("a\r\nb\rc\n", "a\nb\nc\n")
+	if hit != 0 {
+		t.Fatalf("expected no failures, but got: %s", msg)
+	}
+
+	assert.Match(`^\d+$`, "12a")
+	if hit != 1 || msg != `expected "12a" to match "^\\d+$"` {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.NotMatch(`\d+`, "abc123")
+	expected := "expected \"abc123\" not to match \"\\\\d+\", but it matched \"123\" at byte offset 3:\n" +
+		"\t\"abc123\"\n" +
+		"\t    ^"
+	if hit != 2 || msg != expected {
+		t.Fatalf("expected failure:\n%s\nbut got:\n%s", expected, msg)
+	}
+
+	assert.HasPrefix("hello world", "help")
+	expected = "expected \"hello world\" to have prefix \"help\" - Mismatch at byte offset 3:\n" +
+		"\tactual:   \"hello world\"\n" +
+		"\t              ^\n" +
+		"\texpected: \"help\"\n" +
+		"\t              ^"
+	if hit != 3 || msg != expected {
+		t.Fatalf("expected failure:\n%s\nbut got:\n%s", expected, msg)
+	}
+
+	assert.HasSuffix("hello world", "word")
+	if hit != 4 || !strings.Contains(msg, "Mismatch at byte offset 9:") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.EqualFold("Hello", "Help")
+	if hit != 5 || !strings.Contains(msg, "ignoring case - Mismatch at byte offset 3:") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.EqualIgnoreSpace("a  b c", "a b  d")
+	if hit != 6 || !strings.HasPrefix(msg, `actual value "a b c" should be equal to expected value "a b d", ignoring white space - Mismatch at byte offset 4:`) {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.EqualIgnoreLineEndings("a\r\nb", "a\nc")
+	if hit != 7 || !strings.Contains(msg, "ignoring line endings - Mismatch at byte offset 2:") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Match(`(`, "a")
+	if hit != 8 || !strings.HasPrefix(msg, "invalid regular expression \"(\"") {
+		t.Fatalf("expected invalid pattern failure, but got: %s", msg)
+	}
+
+	assert.HasPrefix(42, "4")
+	if hit != 9 || msg != "expected object 'int' to be one of string, []byte or fmt.Stringer" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
+
+func TestTextDiff(t *testing.T) {
+	tests := []struct {
+		actual   string
+		expected string
+		diff     string
+	}{
+		{
+			actual:   "a\nfoo bar baz\nc\n",
+			expected: "a\nfoo qux baz\nc\n",
+			diff:     "--- actual\n+++ expected\n@@ -1,3 +1,3 @@\n a\n-foo [-bar-] baz\n+foo {+qux+} baz\n c\n",
+		},
+		{
+			actual:   "a \nb\n",
+			expected: "a\nb\n",
+			diff:     "--- actual\n+++ expected\n@@ -1,2 +1,2 @@\n-a[-·-]\n+a\n b\n",
+		},
+		{
+			actual:   "a\r\nb\r\n",
+			expected: "a\nb\n",
+			diff:     "--- actual\n+++ expected\n@@ -1,2 +1,2 @@\n-a[-␍-]\n-b[-␍-]\n+a\n+b\n",
+		},
+		{
+			actual:   "\tx\x00\n",
+			expected: "y\n",
+			diff:     "--- actual\n+++ expected\n@@ -1 +1 @@\n-→x\\x00\n+y\n",
+		},
+		{
+			actual:   "same\nold\n",
+			expected: "same\nold\nnew\n",
+			diff:     "--- actual\n+++ expected\n@@ -1,2 +1,3 @@\n same\n old\n+new\n",
+		},
+	}
+	for _, test := range tests {
+		if d := textDiff(test.actual, test.expected, diffContext); d != test.diff {
+			t.Fatalf("expected diff of %q and %q to be:\n%s\nbut got:\n%s", test.actual, test.expected, test.diff, d)
+		}
+	}
+}
+
+func TestEqualMultiline(t *testing.T) {
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	actual := "1\n2\n3\n4\n5\n6\n"
+	expected := "1\n2\n3\nfour\n5\n6\n"
+
+	New(t).Equal(actual, expected)
+	diff := "--- actual\n+++ expected\n@@ -1,6 +1,6 @@\n 1\n 2\n 3\n-4\n+four\n 5\n 6\n"
+	if msg != "actual value (string) should be equal to expected value (string) - Diff:\n"+diff {
+		t.Fatalf("expected multi-line diff, but got:\n%s", msg)
+	}
+
+	New(t, DiffContext(1)).Equal(actual, expected)
+	diff = "--- actual\n+++ expected\n@@ -3,3 +3,3 @@\n 3\n-4\n+four\n 5\n"
+	if !strings.HasSuffix(msg, diff) {
+		t.Fatalf("expected diff with one line of context, but got:\n%s", msg)
+	}
+
+	New(t, DiffContext(-1)).Equal(actual, expected)
+	diff = "--- actual\n+++ expected\n@@ -4 +4 @@\n-4\n+four\n"
+	if !strings.HasSuffix(msg, diff) {
+		t.Fatalf("expected diff without context, but got:\n%s", msg)
+	}
+	if d := unifiedDiff(actual, expected, -2); d != diff {
+		t.Fatalf("expected diff without context, but got:\n%s", d)
+	}
+
+	DeepEqual(New(t), "a\nb", "a\nc")
+	if !strings.HasSuffix(msg, "-b\n+c\n") {
+		t.Fatalf("expected multi-line diff from DeepEqual, but got:\n%s", msg)
+	}
+
+	New(t).Equal("a\n", "a")
+	if msg != `actual value "a\n" (string) should be equal to expected value "a" (string)` {
+		t.Fatalf("expected quoted values, but got:\n%s", msg)
+	}
+
+	fail = failDefault
+}
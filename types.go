@@ -0,0 +1,153 @@
+package is
+
+import (
+	"reflect"
+)
+
+// targetType returns the type pointed to by target, which is typically a
+// nil pointer such as (*io.Reader)(nil). It returns false if target is not a
+// pointer.
+func targetType(target interface{}) (reflect.Type, bool) {
+	t := reflect.TypeOf(target)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return nil, false
+	}
+	return t.Elem(), true
+}
+
+func (self *asserter) Implements(iface interface{}, o interface{}) {
+	self.tb.Helper()
+	it, ok := targetType(iface)
+	if !ok || it.Kind() != reflect.Interface {
+		fail(self, "expected '%s' to be a pointer to an interface type, such as (*io.Reader)(nil)", fullTypeName(reflect.TypeOf(iface)))
+		return
+	}
+	t := reflect.TypeOf(o)
+	if t == nil {
+		fail(self, "expected object '<nil>' to implement '%s'", fullTypeName(it))
+		return
+	}
+	if !t.Implements(it) {
+		fail(self, "expected object '%s' to implement '%s'%s",
+			fullTypeName(t), fullTypeName(it), missingMethod(t, it))
+	}
+}
+
+// missingMethod describes the first method of the interface type it which t
+// does not have, or returns an empty string if there is none.
+func missingMethod(t reflect.Type, it reflect.Type) string {
+	for i := 0; i < it.NumMethod(); i++ {
+		m := it.Method(i)
+		tm, ok := t.MethodByName(m.Name)
+		if !ok {
+			if reflect.PtrTo(t).Implements(it) {
+				return " - Method " + m.Name + " has a pointer receiver"
+			}
+			return " - Missing method: " + m.Name
+		}
+		// The method type of a concrete type includes its receiver.
+		if tm.Type.NumIn() > 0 && t.Kind() != reflect.Interface {
+			in := make([]reflect.Type, tm.Type.NumIn()-1)
+			for j := range in {
+				in[j] = tm.Type.In(j + 1)
+			}
+			out := make([]reflect.Type, tm.Type.NumOut())
+			for j := range out {
+				out[j] = tm.Type.Out(j)
+			}
+			if ft := reflect.FuncOf(in, out, tm.Type.IsVariadic()); ft != m.Type {
+				return " - Method " + m.Name + " has type " + ft.String() + ", but the interface requires " + m.Type.String()
+			}
+		}
+	}
+	return ""
+}
+
+func (self *asserter) AssignableTo(target interface{}, o interface{}) {
+	self.tb.Helper()
+	tt, ok := targetType(target)
+	if !ok {
+		fail(self, "expected target '%s' to be a pointer to the target type, such as (*T)(nil)", fullTypeName(reflect.TypeOf(target)))
+		return
+	}
+	t := reflect.TypeOf(o)
+	if t == nil {
+		fail(self, "expected object '<nil>' to be assignable to '%s'", fullTypeName(tt))
+		return
+	}
+	if !t.AssignableTo(tt) {
+		fail(self, "expected object '%s' to be assignable to '%s'", fullTypeName(t), fullTypeName(tt))
+	}
+}
+
+func (self *asserter) ConvertibleTo(target interface{}, o interface{}) {
+	self.tb.Helper()
+	tt, ok := targetType(target)
+	if !ok {
+		fail(self, "expected target '%s' to be a pointer to the target type, such as (*T)(nil)", fullTypeName(reflect.TypeOf(target)))
+		return
+	}
+	t := reflect.TypeOf(o)
+	if t == nil {
+		fail(self, "expected object '<nil>' to be convertible to '%s'", fullTypeName(tt))
+		return
+	}
+	if !t.ConvertibleTo(tt) {
+		fail(self, "expected object '%s' to be convertible to '%s'", fullTypeName(t), fullTypeName(tt))
+	}
+}
+
+func (self *asserter) Kind(o interface{}, kind reflect.Kind) {
+	self.tb.Helper()
+	if k := reflect.ValueOf(o).Kind(); k != kind {
+		fail(self, "expected object '%s' to be of kind '%s', but it was of kind '%s'",
+			fullTypeName(reflect.TypeOf(o)), kind, k)
+	}
+}
+
+// pointerOf returns the address held by o, which must be a pointer, channel,
+// map or unsafe.Pointer. Functions are not accepted, since the address of a
+// function is that of its code, which closures share.
+func pointerOf(o interface{}) (uintptr, bool) {
+	v := reflect.ValueOf(o)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Chan, reflect.Map, reflect.UnsafePointer:
+		return v.Pointer(), true
+	}
+	return 0, false
+}
+
+func (self *asserter) Same(a interface{}, b interface{}) {
+	self.tb.Helper()
+	ap, aOK := pointerOf(a)
+	bp, bOK := pointerOf(b)
+	if !aOK || !bOK {
+		fail(self, "expected objects '%s' and '%s' to be the same, but both must be one of pointer, chan, map or unsafe.Pointer",
+			fullTypeName(reflect.TypeOf(a)), fullTypeName(reflect.TypeOf(b)))
+		return
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		fail(self, "expected objects '%s' and '%s' to be the same, but their types differ",
+			fullTypeName(reflect.TypeOf(a)), fullTypeName(reflect.TypeOf(b)))
+		return
+	}
+	if ap != bp {
+		fail(self, "expected objects of type '%s' to be the same, but they point to %#x and %#x",
+			fullTypeName(reflect.TypeOf(a)), ap, bp)
+	}
+}
+
+func (self *asserter) NotSame(a interface{}, b interface{}) {
+	self.tb.Helper()
+	ap, aOK := pointerOf(a)
+	bp, bOK := pointerOf(b)
+	if !aOK || !bOK {
+		fail(self, "expected objects '%s' and '%s' not to be the same, but both must be one of pointer, chan, map or unsafe.Pointer",
+			fullTypeName(reflect.TypeOf(a)), fullTypeName(reflect.TypeOf(b)))
+		return
+	}
+	if reflect.TypeOf(a) == reflect.TypeOf(b) && ap == bp {
+		fail(self, "expected objects of type '%s' not to be the same, but both point to %#x",
+			fullTypeName(reflect.TypeOf(a)), ap)
+	}
+}
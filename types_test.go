@@ -0,0 +1,137 @@
+package is
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+type valueReader struct{}
+
+func (valueReader) Read(p []byte) (int, error) { return 0, io.EOF }
+
+type pointerReader struct{}
+
+func (*pointerReader) Read(p []byte) (int, error) { return 0, io.EOF }
+
+type badReader struct{}
+
+func (badReader) Read(p []byte) int { return 0 }
+
+func TestFullTypeName(t *testing.T) {
+	tests := []struct {
+		o        interface{}
+		expected string
+	}{
+		{o: 1, expected: "int"},
+		{o: &bytes.Buffer{}, expected: `*"bytes".Buffer`},
+		{o: []*bytes.Buffer{}, expected: `[]*"bytes".Buffer`},
+		{o: map[string][2]bytes.Buffer{}, expected: `map[string][2]"bytes".Buffer`},
+		{o: make(<-chan bytes.Buffer), expected: `<-chan "bytes".Buffer`},
+		{o: struct{ v int }{}, expected: "struct { v int }"},
+	}
+	for _, test := range tests {
+		if name := fullTypeName(reflect.TypeOf(test.o)); name != test.expected {
+			t.Fatalf("expected full type name %s, but got %s", test.expected, name)
+		}
+	}
+}
+
+func TestTypes(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	buf := &bytes.Buffer{}
+	m := map[string]int{}
+	assert.Implements((*io.Reader)(nil), buf)
+	assert.Implements((*io.Reader)(nil), valueReader{})
+	assert.AssignableTo((*io.Writer)(nil), buf)
+	assert.AssignableTo((*int)(nil), 1)
+	assert.ConvertibleTo((*float64)(nil), 1)
+	assert.ConvertibleTo((*[]byte)(nil), "a")
+	assert.Kind([]int{}, reflect.Slice)
+	assert.Kind(buf, reflect.Ptr)
+	assert.Same(buf, buf)
+	assert.Same(m, m)
+	assert.NotSame(buf, &bytes.Buffer{})
+	assert.NotSame(buf, unsafe.Pointer(buf))
+	if hit != 0 {
+		t.Fatalf("expected no failures, but got: %s", msg)
+	}
+
+	assert.Implements((*io.Writer)(nil), valueReader{})
+	if hit != 1 || msg != `expected object '"github.com/tylerb/is/v3".valueReader' to implement '"io".Writer' - Missing method: Write` {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Implements((*io.Reader)(nil), pointerReader{})
+	if hit != 2 || !strings.HasSuffix(msg, " - Method Read has a pointer receiver") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Implements((*io.Reader)(nil), badReader{})
+	if hit != 3 || !strings.HasSuffix(msg, " - Method Read has type func([]uint8) int, but the interface requires func([]uint8) (int, error)") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Implements(io.Reader(nil), buf)
+	assert.Implements((*bytes.Buffer)(nil), buf)
+	assert.Implements((*io.Reader)(nil), nil)
+	if hit != 6 || msg != `expected object '<nil>' to implement '"io".Reader'` {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.AssignableTo((*io.Writer)(nil), valueReader{})
+	if hit != 7 || msg != `expected object '"github.com/tylerb/is/v3".valueReader' to be assignable to '"io".Writer'` {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.ConvertibleTo((*int)(nil), "a")
+	if hit != 8 || msg != "expected object 'string' to be convertible to 'int'" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.AssignableTo(2, 1)
+	if hit != 9 || msg != "expected target 'int' to be a pointer to the target type, such as (*T)(nil)" {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Kind([]*bytes.Buffer{}, reflect.Map)
+	if hit != 10 || msg != `expected object '[]*"bytes".Buffer' to be of kind 'map', but it was of kind 'slice'` {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Same(buf, &bytes.Buffer{})
+	if hit != 11 || !strings.HasPrefix(msg, `expected objects of type '*"bytes".Buffer' to be the same, but they point to 0x`) {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Same(buf, unsafe.Pointer(buf))
+	if hit != 12 || !strings.HasSuffix(msg, "but their types differ") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.Same(1, 1)
+	assert.NotSame(buf, buf)
+	if hit != 14 || !strings.HasPrefix(msg, `expected objects of type '*"bytes".Buffer' not to be the same, but both point to 0x`) {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	f := func() {}
+	assert.Same(f, f)
+	if hit != 15 || msg != "expected objects 'func()' and 'func()' to be the same, but both must be one of pointer, chan, map or unsafe.Pointer" {
+		t.Fatalf("expected failure for funcs, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
@@ -0,0 +1,367 @@
+package is
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitForTruePoll is the interval at which WaitForTrue calls its function.
+const waitForTruePoll = 100 * time.Millisecond
+
+// PollOption configures how Eventually, Consistently, Never and
+// EventuallyWith wait between calls to their function.
+type PollOption func(p *poller)
+
+// Backoff multiplies the poll interval by factor after every call, up to a
+// maximum of max. A factor of 2 doubles the interval each time. The factor
+// must be at least 1. If max is not positive, the interval grows until it
+// reaches the largest time.Duration, although waits never outlast the
+// timeout.
+func Backoff(factor float64, max time.Duration) PollOption {
+	return func(p *poller) {
+		if !(factor >= 1) {
+			p.err = fmt.Errorf("expected backoff factor to be at least 1, but got: %v", factor)
+			return
+		}
+		p.factor = factor
+		p.max = max
+	}
+}
+
+// Jitter randomly varies each wait by up to fraction of the poll interval in
+// either direction, so that tests polling shared resources do not do so in
+// lockstep. A fraction of 0.1 varies each wait by up to 10%. The fraction
+// must be between 0 and 1.
+func Jitter(fraction float64) PollOption {
+	return func(p *poller) {
+		if !(fraction >= 0 && fraction <= 1) {
+			p.err = fmt.Errorf("expected jitter fraction to be between 0 and 1, but got: %v", fraction)
+			return
+		}
+		p.jitter = fraction
+	}
+}
+
+// poller calls a function repeatedly, waiting between calls.
+type poller struct {
+	interval time.Duration
+	factor   float64
+	max      time.Duration
+	jitter   float64
+
+	// err records the first invalid option.
+	err error
+}
+
+// newPoller returns a poller waiting interval between calls, as modified by
+// opts. It returns an error if interval is not positive, since the function
+// would then be called in a busy loop, or if any of opts is invalid.
+func newPoller(interval time.Duration, opts []PollOption) (*poller, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("expected poll interval to be positive, but got: %v", interval)
+	}
+	p := &poller{interval: interval}
+	for _, opt := range opts {
+		opt(p)
+		if p.err != nil {
+			return nil, p.err
+		}
+	}
+	return p, nil
+}
+
+// maxPollInterval is the interval at which Backoff saturates when it has no
+// maximum.
+const maxPollInterval = time.Duration(math.MaxInt64)
+
+// next returns how long to wait before the next call, and advances the
+// interval according to the backoff.
+func (p *poller) next() time.Duration {
+	d := p.interval
+	if p.jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * p.jitter * float64(d))
+	}
+	if p.factor > 1 {
+		// The product is bounded before converting it back to a
+		// time.Duration, which would otherwise overflow.
+		bound := maxPollInterval
+		if p.max > 0 {
+			bound = p.max
+		}
+		if next := float64(p.interval) * p.factor; next >= float64(bound) {
+			p.interval = bound
+		} else {
+			p.interval = time.Duration(next)
+		}
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+// waitLimit describes what ended a wait before its function succeeded.
+type waitLimit int
+
+const (
+	limitNone waitLimit = iota
+	limitTimeout
+	limitDeadline
+	limitContext
+)
+
+// waitResult is the outcome of a wait.
+type waitResult struct {
+	ok    bool
+	calls int
+	limit waitLimit
+
+	// margin is the time left before the test deadline when the wait was
+	// stopped by it.
+	margin time.Duration
+
+	// err is the error of the context when the wait was stopped by it.
+	err error
+}
+
+// describe explains why a wait for timeout ended, for use after "did not
+// return true" and similar phrases.
+func (r waitResult) describe(timeout time.Duration) string {
+	switch r.limit {
+	case limitDeadline:
+		return fmt.Sprintf("before the test deadline, which was the limit (stopped %v before it)", r.margin)
+	case limitContext:
+		return fmt.Sprintf("before the context was done: %v", r.err)
+	}
+	return fmt.Sprintf("within the timeout of %v", timeout)
+}
+
+// deadlineMargin is the time left before a test's deadline when waiting
+// assertions are stopped, so that they fail cleanly rather than the test
+// binary panicking. Tests with less than twice this time left keep half of
+// what remains.
+const deadlineMargin = time.Second
+
+// run calls f until it returns true, ctx is done or the deadline is reached,
+// making a final call once the deadline is reached. A zero deadline waits
+// until ctx is done. The deadline is brought forward if the test would reach
+// its own deadline first.
+func (p *poller) run(self *asserter, ctx context.Context, deadline time.Time, f func() bool) waitResult {
+	limit := limitTimeout
+	var margin time.Duration
+	if d, ok := self.tb.(interface{ Deadline() (time.Time, bool) }); ok {
+		if testDeadline, ok := d.Deadline(); ok {
+			margin = deadlineMargin
+			if remaining := time.Until(testDeadline); remaining < 2*margin {
+				margin = remaining / 2
+			}
+			if clamped := testDeadline.Add(-margin); deadline.IsZero() || clamped.Before(deadline) {
+				deadline = clamped
+				limit = limitDeadline
+			}
+		}
+	}
+
+	for calls := 1; ; calls++ {
+		if f() {
+			return waitResult{ok: true, calls: calls}
+		}
+		if err := ctx.Err(); err != nil {
+			return waitResult{calls: calls, limit: limitContext, err: err}
+		}
+		d := p.next()
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return waitResult{calls: calls, limit: limit, margin: margin}
+			}
+			if d > remaining {
+				d = remaining
+			}
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// goroutines returns the stacks of all goroutines, for failures caused by a
+// wait running out of time.
+func goroutines() string {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return " - Goroutines:\n" + string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// poller returns a poller for the Eventually family, failing if poll or
+// opts are invalid.
+func (self *asserter) poller(poll time.Duration, opts []PollOption) (*poller, bool) {
+	self.tb.Helper()
+	p, err := newPoller(poll, opts)
+	if err != nil {
+		fail(self, "%v", err)
+		return nil, false
+	}
+	return p, true
+}
+
+func (self *asserter) Eventually(timeout time.Duration, poll time.Duration, f func() bool, opts ...PollOption) {
+	self.tb.Helper()
+	p, ok := self.poller(poll, opts)
+	if !ok {
+		return
+	}
+	r := p.run(self, context.Background(), time.Now().Add(timeout), f)
+	if !r.ok {
+		fail(self, "function did not return true %s (%d calls)%s", r.describe(timeout), r.calls, goroutines())
+	}
+}
+
+func (self *asserter) Consistently(duration time.Duration, poll time.Duration, f func() bool, opts ...PollOption) {
+	self.tb.Helper()
+	start := time.Now()
+	p, ok := self.poller(poll, opts)
+	if !ok {
+		return
+	}
+	r := p.run(self, context.Background(), start.Add(duration), func() bool { return !f() })
+	switch {
+	case r.ok:
+		fail(self, "function returned false on call %d after %v, but was expected to return true for %v",
+			r.calls, time.Since(start).Round(time.Millisecond), duration)
+	case r.limit != limitTimeout:
+		fail(self, "function returned true on every call, but was stopped %s, after %v of the %v it was expected to return true for (%d calls)%s",
+			r.describe(duration), time.Since(start).Round(time.Millisecond), duration, r.calls, goroutines())
+	}
+}
+
+func (self *asserter) Never(duration time.Duration, poll time.Duration, f func() bool, opts ...PollOption) {
+	self.tb.Helper()
+	start := time.Now()
+	p, ok := self.poller(poll, opts)
+	if !ok {
+		return
+	}
+	r := p.run(self, context.Background(), start.Add(duration), f)
+	switch {
+	case r.ok:
+		fail(self, "function returned true on call %d after %v, but was expected never to return true within %v",
+			r.calls, time.Since(start).Round(time.Millisecond), duration)
+	case r.limit != limitTimeout:
+		fail(self, "function never returned true, but was stopped %s, after %v of the %v it was expected never to return true within (%d calls)%s",
+			r.describe(duration), time.Since(start).Round(time.Millisecond), duration, r.calls, goroutines())
+	}
+}
+
+func (self *asserter) EventuallyWith(timeout time.Duration, poll time.Duration, f func(a Asserter), opts ...PollOption) {
+	self.tb.Helper()
+	var failures []string
+	p, ok := self.poller(poll, opts)
+	if !ok {
+		return
+	}
+	r := p.run(self, context.Background(), time.Now().Add(timeout), func() bool {
+		failures = self.attempt(f)
+		return len(failures) == 0
+	})
+	if !r.ok {
+		fail(self, "assertions did not pass %s (%d attempts) - Last failures:\n\t%s%s",
+			r.describe(timeout), r.calls, strings.Join(failures, "\n\t"), goroutines())
+	}
+}
+
+// errAttemptFailed is used to stop an attempt made by EventuallyWith when an
+// assertion fails.
+var errAttemptFailed = errors.New("is: attempt failed")
+
+// collectTB is a testing.TB that records failures instead of reporting them,
+// so that the assertions of an attempt made by EventuallyWith can fail
+// without failing the test.
+type collectTB struct {
+	testing.TB
+	failures []string
+	failed   bool
+}
+
+func (c *collectTB) Error(args ...interface{}) {
+	c.failed = true
+	c.failures = append(c.failures, fmt.Sprint(args...))
+}
+
+func (c *collectTB) Errorf(format string, args ...interface{}) {
+	c.failed = true
+	c.failures = append(c.failures, fmt.Sprintf(format, args...))
+}
+
+func (c *collectTB) Fatal(args ...interface{}) {
+	c.Error(args...)
+	c.FailNow()
+}
+
+func (c *collectTB) Fatalf(format string, args ...interface{}) {
+	c.Errorf(format, args...)
+	c.FailNow()
+}
+
+func (c *collectTB) Fail() {
+	c.failed = true
+}
+
+func (c *collectTB) FailNow() {
+	c.failed = true
+	panic(errAttemptFailed)
+}
+
+func (c *collectTB) Failed() bool {
+	return c.failed
+}
+
+// Deadline returns the deadline of the test, if it has one, so that waits
+// nested in EventuallyWith are stopped before it as well.
+func (c *collectTB) Deadline() (time.Time, bool) {
+	if d, ok := c.TB.(interface{ Deadline() (time.Time, bool) }); ok {
+		return d.Deadline()
+	}
+	return time.Time{}, false
+}
+
+// attempt calls f with an Asserter that collects failures, and returns
+// them. A failing assertion ends the attempt.
+func (self *asserter) attempt(f func(a Asserter)) []string {
+	c := &collectTB{TB: self.tb}
+	a := &asserter{
+		tb:          c,
+		strict:      true,
+		cmpOpts:     self.cmpOpts,
+		strictTypes: self.strictTypes,
+		comparers:   self.comparers,
+		context:     self.context,
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != nil && r != errAttemptFailed {
+				panic(r)
+			}
+		}()
+		f(a)
+	}()
+	if c.failed && len(c.failures) == 0 {
+		return []string{"the test was marked as failed"}
+	}
+	return c.failures
+}
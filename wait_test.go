@@ -0,0 +1,234 @@
+package is
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoller(t *testing.T) {
+	p, _ := newPoller(10*time.Millisecond, []PollOption{Backoff(2, 50*time.Millisecond)})
+	var waits []time.Duration
+	for i := 0; i < 5; i++ {
+		waits = append(waits, p.next())
+	}
+	expected := []time.Duration{10, 20, 40, 50, 50}
+	for i, w := range waits {
+		if w != expected[i]*time.Millisecond {
+			t.Fatalf("expected waits of %v ms, but got %v", expected, waits)
+		}
+	}
+
+	p, _ = newPoller(100*time.Millisecond, []PollOption{Jitter(0.5)})
+	for i := 0; i < 100; i++ {
+		if w := p.next(); w < 50*time.Millisecond || w > 150*time.Millisecond {
+			t.Fatalf("expected jittered wait between 50ms and 150ms, but got %v", w)
+		}
+	}
+
+	p, _ = newPoller(time.Hour, []PollOption{Backoff(1e6, 0)})
+	for i := 0; i < 10; i++ {
+		p.next()
+	}
+	if w := p.next(); w != maxPollInterval {
+		t.Fatalf("expected backoff to saturate at %v, but got %v", maxPollInterval, w)
+	}
+
+	invalid := []struct {
+		poll     time.Duration
+		opts     []PollOption
+		expected string
+	}{
+		{0, nil, "expected poll interval to be positive, but got: 0s"},
+		{-time.Second, nil, "expected poll interval to be positive, but got: -1s"},
+		{time.Second, []PollOption{Backoff(0.5, time.Minute)}, "expected backoff factor to be at least 1, but got: 0.5"},
+		{time.Second, []PollOption{Jitter(2)}, "expected jitter fraction to be between 0 and 1, but got: 2"},
+	}
+	for _, test := range invalid {
+		if _, err := newPoller(test.poll, test.opts); err == nil || err.Error() != test.expected {
+			t.Fatalf("expected error %q, but got: %v", test.expected, err)
+		}
+	}
+}
+
+func TestEventually(t *testing.T) {
+	assert := New(t)
+
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		if _, ok := is.tb.(*collectTB); ok {
+			failDefault(is, format, args...)
+			return
+		}
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	calls := 0
+	assert.Eventually(time.Second, time.Millisecond, func() bool {
+		calls++
+		return calls == 3
+	})
+	assert.Consistently(20*time.Millisecond, 5*time.Millisecond, func() bool { return true })
+	assert.Never(20*time.Millisecond, 5*time.Millisecond, func() bool { return false }, Jitter(0.2))
+	attempts := 0
+	assert.EventuallyWith(time.Second, time.Millisecond, func(a Asserter) {
+		attempts++
+		a.Equal(attempts, 3)
+		a.True(true)
+	})
+	if hit != 0 {
+		t.Fatalf("expected no failures, but got: %s", msg)
+	}
+	if calls != 3 || attempts != 3 {
+		t.Fatalf("expected 3 calls and 3 attempts, but got %d and %d", calls, attempts)
+	}
+
+	assert.Eventually(20*time.Millisecond, 5*time.Millisecond, func() bool { return false }, Backoff(2, time.Second))
+	if hit != 1 || !strings.HasPrefix(msg, "function did not return true within the timeout of 20ms (") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	calls = 0
+	assert.Consistently(time.Second, time.Millisecond, func() bool {
+		calls++
+		return calls < 3
+	})
+	if hit != 2 || !strings.HasPrefix(msg, "function returned false on call 3 after ") ||
+		!strings.HasSuffix(msg, "but was expected to return true for 1s") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	calls = 0
+	assert.Never(time.Second, time.Millisecond, func() bool {
+		calls++
+		return calls == 2
+	})
+	if hit != 3 || !strings.HasPrefix(msg, "function returned true on call 2 after ") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert.EventuallyWith(20*time.Millisecond, 5*time.Millisecond, func(a Asserter) {
+		a.Lax(func(lax Asserter) {
+			lax.Equal(1, 2)
+			lax.True(false)
+		})
+	})
+	if hit != 4 || !strings.HasPrefix(msg, "assertions did not pass within the timeout of 20ms (") ||
+		!strings.Contains(msg, "Last failures:\n\tactual value '1' (int) should be equal to expected value '2' (int)\n\texpected boolean to be true\n\tat least one assertion in the Lax function failed") {
+		t.Fatalf("expected failure with the last assertion failures, but got: %s", msg)
+	}
+
+	calls = 0
+	assert.Eventually(time.Second, 0, func() bool {
+		calls++
+		return false
+	})
+	if hit != 5 || calls != 0 || msg != "expected poll interval to be positive, but got: 0s" {
+		t.Fatalf("expected failure for a poll interval of 0, but got: %s", msg)
+	}
+
+	assert.WaitForTrue(10*time.Millisecond, func() bool { return false })
+	if hit != 6 || !strings.HasPrefix(msg, "function did not return true within the timeout of 10ms - Goroutines:\n") ||
+		!strings.Contains(msg, "TestEventually") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
+
+// deadlineTB is a testing.TB with a deadline, as a *testing.T run with
+// -timeout has.
+type deadlineTB struct {
+	testing.TB
+	deadline time.Time
+}
+
+func (d deadlineTB) Deadline() (time.Time, bool) {
+	return d.deadline, true
+}
+
+func TestWaitLimits(t *testing.T) {
+	hit := 0
+	msg := ""
+	fail = func(is *asserter, format string, args ...interface{}) {
+		if _, ok := is.tb.(*collectTB); ok {
+			failDefault(is, format, args...)
+			return
+		}
+		hit++
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	assert := New(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.WaitForTrueCtx(ctx, func() bool { return false })
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected WaitForTrueCtx to stop when the context was done, but it took %v", elapsed)
+	}
+	if hit != 1 || !strings.HasPrefix(msg, "function did not return true before the context was done: context deadline exceeded - Goroutines:\n") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	calls := 0
+	assert.WaitForTrueCtx(context.Background(), func() bool {
+		calls++
+		return calls == 2
+	})
+	if hit != 1 {
+		t.Fatalf("expected no failure, but got: %s", msg)
+	}
+
+	assert = New(deadlineTB{TB: t, deadline: time.Now().Add(100 * time.Millisecond)})
+	start = time.Now()
+	assert.Eventually(time.Hour, 5*time.Millisecond, func() bool { return false })
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected Eventually to stop before the test deadline, but it took %v", elapsed)
+	}
+	if hit != 2 || !strings.HasPrefix(msg, "function did not return true before the test deadline, which was the limit (stopped ") ||
+		!strings.Contains(msg, " - Goroutines:\n") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert = New(deadlineTB{TB: t, deadline: time.Now().Add(50 * time.Millisecond)})
+	assert.Consistently(time.Hour, 5*time.Millisecond, func() bool { return true })
+	if hit != 3 || !strings.HasPrefix(msg, "function returned true on every call, but was stopped before the test deadline") ||
+		!strings.Contains(msg, " - Goroutines:\n") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert = New(deadlineTB{TB: t, deadline: time.Now().Add(50 * time.Millisecond)})
+	assert.Never(time.Hour, 5*time.Millisecond, func() bool { return false })
+	if hit != 4 || !strings.HasPrefix(msg, "function never returned true, but was stopped before the test deadline") ||
+		!strings.Contains(msg, " - Goroutines:\n") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	// A wait nested in EventuallyWith is stopped before the test deadline
+	// too, rather than running for its whole timeout.
+	assert = New(deadlineTB{TB: t, deadline: time.Now().Add(100 * time.Millisecond)})
+	start = time.Now()
+	assert.EventuallyWith(time.Hour, 5*time.Millisecond, func(a Asserter) {
+		a.Eventually(time.Hour, 5*time.Millisecond, func() bool { return false })
+	})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the nested wait to stop before the test deadline, but it took %v", elapsed)
+	}
+	if hit != 5 || !strings.HasPrefix(msg, "assertions did not pass before the test deadline") ||
+		!strings.Contains(msg, "function did not return true before the test deadline") {
+		t.Fatalf("expected failure, but got: %s", msg)
+	}
+
+	assert = New(deadlineTB{TB: t, deadline: time.Now().Add(time.Hour)})
+	assert.Never(10*time.Millisecond, 5*time.Millisecond, func() bool { return false })
+	if hit != 5 {
+		t.Fatalf("expected no failure for a wait ending before the test deadline, but got: %s", msg)
+	}
+
+	fail = failDefault
+}
@@ -2,9 +2,11 @@ package is
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"reflect"
+	"runtime/debug"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -43,27 +45,63 @@ func isNil(o interface{}) bool {
 	return false
 }
 
-func isZero(o interface{}) bool {
-	if o == nil {
-		return true
+// typedNilNote explains why a and b are not equal if one is an untyped nil
+// and the other a typed nil held in an interface, and otherwise returns an
+// empty string.
+func typedNilNote(a interface{}, b interface{}) string {
+	typed := a
+	if a == nil {
+		typed = b
+	} else if b != nil {
+		return ""
+	}
+	if typed == nil || !isNil(typed) {
+		return ""
+	}
+	return fmt.Sprintf(" - A nil %s held in an interface is not equal to an untyped nil; use Nil to accept either, or NilInterface to require an untyped nil",
+		objectTypeName(typed))
+}
+
+var isZeroerType = reflect.TypeOf((*interface{ IsZero() bool })(nil)).Elem()
+
+// isZero reports whether o is zero, along with a description of the rule
+// that decided it:
+//
+//   - nil is zero
+//   - a value with an IsZero() bool method, such as time.Time, is zero if
+//     the method returns true
+//   - a pointer is zero if the value it points to is zero
+//   - a slice, map or chan is zero if its length is 0
+//   - an array is zero if all its elements are zero values
+//   - any other value is zero if it is equal to the zero value of its type
+func isZero(o interface{}) (bool, string) {
+	if isNil(o) {
+		return true, "nil"
 	}
 	v := reflect.ValueOf(o)
+	if v.Type().Implements(isZeroerType) {
+		return o.(interface{ IsZero() bool }).IsZero(), "IsZero method"
+	}
+	if reflect.PtrTo(v.Type()).Implements(isZeroerType) {
+		return addressable(v).Addr().Interface().(interface{ IsZero() bool }).IsZero(), "IsZero method"
+	}
 	switch v.Kind() {
 	case reflect.Ptr:
-		return reflect.DeepEqual(o,
-			reflect.New(v.Type().Elem()).Interface())
-	case reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
-		if v.Len() == 0 {
-			return true
-		}
-		return false
+		zero, rule := isZero(v.Elem().Interface())
+		return zero, "dereferenced pointer, then " + rule
+	case reflect.Slice, reflect.Map, reflect.Chan:
+		return v.Len() == 0, fmt.Sprintf("length (%d)", v.Len())
+	case reflect.Array:
+		return v.IsZero(), "element-wise zero value"
 	default:
-		return reflect.DeepEqual(o,
-			reflect.Zero(v.Type()).Interface())
+		return reflect.DeepEqual(o, reflect.Zero(v.Type()).Interface()), "zero value of the type"
 	}
 }
 
-func isEqual(a interface{}, b interface{}) bool {
+// isEqual reports whether a and b are equal, consulting the provided
+// comparers, followed by those registered with RegisterComparer, at every
+// level of nesting.
+func isEqual(a interface{}, b interface{}, c *comparers) bool {
 	if isNil(a) || isNil(b) {
 		if isNil(a) && !isNil(b) {
 			return false
@@ -74,30 +112,374 @@ func isEqual(a interface{}, b interface{}) bool {
 		return a == b
 	}
 
-	// Call a.Equaler if it is implemented
-	if e, ok := a.(Equaler); ok {
-		return e.Equal(b)
+	// Call Equaler or EqualityChecker if either value implements them. Nested
+	// values are checked in the same way by deepEqual.
+	if equal, ok := checkerEqual(a, b); ok {
+		return equal
 	}
 
-	// Call a.EqualityChecker if it is implemented
-	if e, ok := a.(EqualityChecker); ok {
-		return e.IsEqual(b)
-	}
-
-	if reflect.DeepEqual(a, b) {
+	aValue := reflect.ValueOf(a)
+	bValue := reflect.ValueOf(b)
+	if deepEqual(aValue, bValue, c) {
 		return true
 	}
 
-	aValue := reflect.ValueOf(a)
-	bValue := reflect.ValueOf(b)
 	// Convert types and compare
 	if bValue.Type().ConvertibleTo(aValue.Type()) {
-		return reflect.DeepEqual(a, bValue.Convert(aValue.Type()).Interface())
+		return deepEqual(aValue, bValue.Convert(aValue.Type()), c)
 	}
 
 	return false
 }
 
+// typeMismatch describes the difference between the dynamic types of a and b.
+// Package paths are included when the short type names are identical.
+func typeMismatch(a interface{}, b interface{}) string {
+	aName, bName := objectTypeName(a), objectTypeName(b)
+	if aName == bName {
+		aName = fullTypeName(reflect.TypeOf(a))
+		bName = fullTypeName(reflect.TypeOf(b))
+	}
+	return fmt.Sprintf(" - Types: %s != %s", aName, bName)
+}
+
+// fullTypeName returns the name of t qualified with its full package path.
+// The element types of composite types are qualified in the same way.
+func fullTypeName(t reflect.Type) string {
+	if t == nil {
+		return "<nil>"
+	}
+	if t.Name() != "" && t.PkgPath() != "" {
+		return fmt.Sprintf("%q.%s", t.PkgPath(), t.Name())
+	}
+	if t.Name() != "" {
+		return t.String()
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + fullTypeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + fullTypeName(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), fullTypeName(t.Elem()))
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", fullTypeName(t.Key()), fullTypeName(t.Elem()))
+	case reflect.Chan:
+		switch t.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + fullTypeName(t.Elem())
+		case reflect.SendDir:
+			return "chan<- " + fullTypeName(t.Elem())
+		}
+		return "chan " + fullTypeName(t.Elem())
+	}
+	return t.String()
+}
+
+// listElements returns the elements of o if it is an array or slice.
+func listElements(o interface{}) ([]interface{}, bool) {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Array && v.Kind() != reflect.Slice {
+		return nil, false
+	}
+	elems := make([]interface{}, v.Len())
+	for i := range elems {
+		elems[i] = v.Index(i).Interface()
+	}
+	return elems, true
+}
+
+// elementsDiff compares two lists of elements as multisets, using equal to
+// match elements of actual with elements of expected. It returns a line for
+// each distinct element that is missing from actual or extra in actual,
+// including how many times it was expected and found when the difference is
+// in the number of duplicates. It returns an empty string if the lists match.
+func elementsDiff(actual []interface{}, expected []interface{}, equal func(a, b interface{}) bool) string {
+	// Equality may not be transitive, for example when EqualityChecker or
+	// numeric conversion is involved, so elements are paired with a maximum
+	// bipartite matching rather than first come, first served. equal is
+	// always called with the actual element first.
+	candidates := make([][]int, len(expected))
+	for j, e := range expected {
+		for i, a := range actual {
+			if equal(a, e) {
+				candidates[j] = append(candidates[j], i)
+			}
+		}
+	}
+	matchOf := make([]int, len(actual))
+	for i := range matchOf {
+		matchOf[i] = -1
+	}
+	var augment func(j int, visited []bool) bool
+	augment = func(j int, visited []bool) bool {
+		for _, i := range candidates[j] {
+			if visited[i] {
+				continue
+			}
+			visited[i] = true
+			if matchOf[i] < 0 || augment(matchOf[i], visited) {
+				matchOf[i] = j
+				return true
+			}
+		}
+		return false
+	}
+	var missing []interface{}
+	for j, e := range expected {
+		if !augment(j, make([]bool, len(actual))) {
+			missing = append(missing, e)
+		}
+	}
+	var extra []interface{}
+	for i, a := range actual {
+		if matchOf[i] < 0 {
+			extra = append(extra, a)
+		}
+	}
+
+	// countLike counts the elements of list equal to v, which comes from the
+	// same side as list.
+	countLike := func(list []interface{}, v interface{}) int {
+		n := 0
+		for _, o := range list {
+			if equal(o, v) {
+				n++
+			}
+		}
+		return n
+	}
+	var b bytes.Buffer
+	write := func(label string, elems []interface{}, counts func(v interface{}) (int, int)) {
+		var seen []interface{}
+		for _, v := range elems {
+			if countLike(seen, v) > 0 {
+				continue
+			}
+			seen = append(seen, v)
+			fmt.Fprintf(&b, "\t%s: %s", label, formatValue(reflect.ValueOf(v)))
+			if n, m := counts(v); n > 0 && m > 0 {
+				fmt.Fprintf(&b, " (expected %d, found %d)", n, m)
+			}
+			b.WriteString("\n")
+		}
+	}
+	write("missing", missing, func(e interface{}) (int, int) {
+		found := 0
+		for _, a := range actual {
+			if equal(a, e) {
+				found++
+			}
+		}
+		return countLike(expected, e), found
+	})
+	write("extra", extra, func(a interface{}) (int, int) {
+		wanted := 0
+		for _, e := range expected {
+			if equal(a, e) {
+				wanted++
+			}
+		}
+		return wanted, countLike(actual, a)
+	})
+	return b.String()
+}
+
+// contains searches container for elem. Strings, and byte slices searched
+// for a string or byte slice, are searched for a substring; slices and
+// arrays are searched for an element equal to elem; maps are searched for a
+// key equal to elem. It returns a description of where elem was found, and
+// an error if container cannot be searched.
+func contains(container interface{}, elem interface{}, equal func(a, b interface{}) bool) (found bool, where string, err error) {
+	if s, ok := container.(string); ok {
+		var sub string
+		switch e := elem.(type) {
+		case string:
+			sub = e
+		case rune:
+			sub = string(e)
+		case []byte:
+			sub = string(e)
+		default:
+			return false, "", fmt.Errorf("cannot search string for '%s'", objectTypeName(elem))
+		}
+		if i := strings.Index(s, sub); i >= 0 {
+			return true, fmt.Sprintf("byte offset %d", i), nil
+		}
+		return false, "", nil
+	}
+	if b, ok := container.([]byte); ok {
+		var sub []byte
+		switch e := elem.(type) {
+		case string:
+			sub = []byte(e)
+		case []byte:
+			sub = e
+		}
+		if sub != nil {
+			if i := bytes.Index(b, sub); i >= 0 {
+				return true, fmt.Sprintf("byte offset %d", i), nil
+			}
+			return false, "", nil
+		}
+	}
+
+	v := reflect.ValueOf(container)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if equal(v.Index(i).Interface(), elem) {
+				return true, fmt.Sprintf("index %d", i), nil
+			}
+		}
+		return false, "", nil
+	case reflect.Map:
+		keys := v.MapKeys()
+		sortValues(keys)
+		for _, k := range keys {
+			if equal(k.Interface(), elem) {
+				return true, fmt.Sprintf("key '%v'", k), nil
+			}
+		}
+		return false, "", nil
+	case reflect.Chan:
+		return false, "", fmt.Errorf("cannot search '%s' without receiving from it", objectTypeName(container))
+	}
+	return false, "", fmt.Errorf("'%s' is not one of string, array, slice or map", objectTypeName(container))
+}
+
+// maxPrintLen is the length beyond which values printed in failure
+// messages by truncated are cut short.
+const maxPrintLen = 200
+
+// truncated formats o with %v, cutting the result short if it is longer
+// than maxPrintLen.
+func truncated(o interface{}) string {
+	s := fmt.Sprintf("%v", o)
+	if len(s) <= maxPrintLen {
+		return s
+	}
+	cut := maxPrintLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... (%d more bytes)", s[:cut], len(s)-cut)
+}
+
+var (
+	lenType = reflect.TypeOf((*interface{ Len() int })(nil)).Elem()
+	capType = reflect.TypeOf((*interface{ Cap() int })(nil)).Elem()
+)
+
+// lengthOf returns the length of o, which may be an array, pointer to an
+// array, channel, map, slice or string, or have a Len() int method. Nil
+// values are treated as having a length of zero. It returns false if o has
+// no length.
+func lengthOf(o interface{}) (int, bool) {
+	v := reflect.ValueOf(o)
+	switch v.Kind() {
+	case reflect.Array, reflect.Chan, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len(), true
+	case reflect.Ptr:
+		if v.Type().Elem().Kind() == reflect.Array {
+			if v.IsNil() {
+				return 0, true
+			}
+			return v.Len(), true
+		}
+	}
+	if v.IsValid() && v.Type().Implements(lenType) {
+		if isNil(o) {
+			return 0, true
+		}
+		return o.(interface{ Len() int }).Len(), true
+	}
+	return 0, false
+}
+
+// capacityOf returns the capacity of o, which may be an array, pointer to an
+// array, channel or slice, or have a Cap() int method. It returns false if o
+// has no capacity.
+func capacityOf(o interface{}) (int, bool) {
+	v := reflect.ValueOf(o)
+	switch v.Kind() {
+	case reflect.Array, reflect.Chan, reflect.Slice:
+		return v.Cap(), true
+	case reflect.Ptr:
+		if v.Type().Elem().Kind() == reflect.Array {
+			if v.IsNil() {
+				return 0, true
+			}
+			return v.Cap(), true
+		}
+	}
+	if v.IsValid() && v.Type().Implements(capType) {
+		if isNil(o) {
+			return 0, true
+		}
+		return o.(interface{ Cap() int }).Cap(), true
+	}
+	return 0, false
+}
+
+// capturePanic calls fn and reports whether it panicked. If it did, the
+// recovered value and the stack of the panicking goroutine are returned.
+func capturePanic(fn func()) (panicked bool, recovered interface{}, stack []byte) {
+	panicked = true
+	defer func() {
+		if panicked {
+			recovered = recover()
+			stack = debug.Stack()
+		}
+	}()
+	fn()
+	panicked = false
+	return
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// maxErrorDepth limits how deeply errorChain follows wrapped errors, in case
+// an error wraps itself.
+const maxErrorDepth = 32
+
+// errorChain returns a description of err and every error it wraps, for
+// inclusion in a failure message. Errors wrapping several errors, such as
+// those returned by errors.Join, are printed as a tree.
+func errorChain(err error) string {
+	if isNil(err) {
+		return " - Error chain: <nil>"
+	}
+	var b bytes.Buffer
+	b.WriteString(" - Error chain:\n")
+	writeErrorChain(&b, err, 0)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func writeErrorChain(b *bytes.Buffer, err error, depth int) {
+	if isNil(err) {
+		// A typed nil error would most likely panic in its Error method.
+		fmt.Fprintf(b, "\t%s%T: <nil>\n", strings.Repeat("  ", depth), err)
+		return
+	}
+	fmt.Fprintf(b, "\t%s%T: %q\n", strings.Repeat("  ", depth), err, err.Error())
+	if depth == maxErrorDepth {
+		return
+	}
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if next := u.Unwrap(); next != nil {
+			writeErrorChain(b, next, depth+1)
+		}
+	case interface{ Unwrap() []error }:
+		for _, next := range u.Unwrap() {
+			if next != nil {
+				writeErrorChain(b, next, depth+1)
+			}
+		}
+	}
+}
+
 // fail is a function variable that is called by test functions when they
 // fail. It is overridden in test code for this package.
 var fail = failDefault
@@ -120,53 +502,25 @@ func failDefault(is *asserter, format string, args ...interface{}) {
 	}
 }
 
-func diff(actual interface{}, expected interface{}) string {
-	aKind := reflect.TypeOf(actual).Kind()
-	eKind := reflect.TypeOf(expected).Kind()
-	if aKind != eKind {
-		return ""
-	}
-	if aKind != reflect.Slice && aKind != reflect.Map {
-		return ""
-	}
-	f := func(src, dest interface{}) bool {
-		bytes, err := json.Marshal(src)
-		if err != nil {
-			return false
-		}
-		err = json.Unmarshal(bytes, &dest)
-		if err != nil {
-			return false
-		}
-		return true
-	}
-	var s string
-	switch aKind {
-	case reflect.Slice:
-		var aSlice []interface{}
-		var eSlice []interface{}
-		if !f(actual, &aSlice) {
-			return ""
-		}
-		if !f(expected, &eSlice) {
-			return ""
+// cmpEqual reports whether a and b are equal according to cmp.Equal with the
+// provided options. cmp.Equal panics if it encounters values it cannot
+// compare, such as unexported fields without a suitable option; that panic is
+// returned as an error instead.
+func cmpEqual(a interface{}, b interface{}, opts []cmp.Option) (equal bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
 		}
-		s = cmp.Diff(aSlice, eSlice)
-	case reflect.Map:
-		var aMap map[interface{}]interface{}
-		var eMap map[interface{}]interface{}
-		if !f(actual, &aMap) {
-			return ""
-		}
-		if !f(expected, &eMap) {
-			return ""
-		}
-		s = cmp.Diff(aMap, eMap)
-	default:
-		return ""
-	}
+	}()
+	return cmp.Equal(a, b, opts...), nil
+}
+
+// cmpDiff returns the output of cmp.Diff for a and b with the provided
+// options, formatted for inclusion in a failure message.
+func cmpDiff(a interface{}, b interface{}, opts []cmp.Option) string {
+	s := cmp.Diff(a, b, opts...)
 	if s != "" {
-		return " - Diff:\n" + s
+		return " - Diff (-actual +expected):\n" + s
 	}
 	return ""
 }